@@ -1,7 +1,9 @@
 package main
 
 import (
+	"github.com/pkg/errors"
 	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/pzhenzhou/crypto-prototype/pkg/crypto"
 	"github.com/pzhenzhou/crypto-prototype/pkg/web"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -9,8 +11,12 @@ import (
 )
 
 const (
-	PortArg   string = "port"
-	ConfigArg string = "config"
+	PortArg    string = "port"
+	ConfigArg  string = "config"
+	EntropyArg string = "entropy"
+	HwRngArg   string = "hwrng"
+	NetworkArg string = "network"
+	PolicyArg  string = "policy"
 )
 
 var (
@@ -21,7 +27,11 @@ var (
 
 func main() {
 	pflag.Int(PortArg, 4567, "http server port. If not set the default is 4567")
-	pflag.String(ConfigArg, "./config", "config absolute path. by default ./config")
+	pflag.String(ConfigArg, "", "config absolute path. overrides the embedded word lists, by default only the embedded word lists are used")
+	pflag.String(EntropyArg, "os", "mnemonic entropy source. os, hwrng or mixed (os and hwrng). If not set the default is os")
+	pflag.String(HwRngArg, crypto.HardwareRNGDevicePath, "hardware random number generator device of the hwrng and mixed entropy sources")
+	pflag.String(NetworkArg, string(crypto.MainNet), "default network of the generated addresses and keys. mainnet, testnet3, signet or regtest, a request may override it. CRYPTO_NETWORK sets it too")
+	pflag.String(PolicyArg, "", "exposure policy JSON absolute path. the address fields a response may carry per environment and API key, by default prod only returns the address and public keys")
 	pflag.Parse()
 	var flagErr = viper.BindPFlags(pflag.CommandLine)
 	if flagErr != nil {
@@ -32,6 +42,48 @@ func main() {
 	config = viper.GetString(ConfigArg)
 	logger.Info("crypto http service will be start", zap.Any("port", port),
 		zap.Any("configPath", config))
-	common.LoadWordsList(config)
+	if config != "" {
+		if loadErr := common.LoadWordsList(config); loadErr != nil {
+			logger.Error("crypto load word lists error", zap.Error(loadErr))
+			panic(loadErr)
+		}
+	}
+	entropySource, sourceErr := newEntropySource(viper.GetString(EntropyArg), viper.GetString(HwRngArg))
+	if sourceErr != nil {
+		logger.Error("crypto entropy source error", zap.Error(sourceErr))
+		panic(sourceErr)
+	}
+	crypto.SetDefaultEntropySource(entropySource)
+	network, networkErr := crypto.ParseNetwork(viper.GetString(NetworkArg))
+	if networkErr == nil {
+		networkErr = crypto.SetDefaultNetwork(network)
+	}
+	if networkErr != nil {
+		logger.Error("crypto network error", zap.Error(networkErr))
+		panic(networkErr)
+	}
+	logger.Info("crypto default network", zap.Any("network", network))
+	if policyPath := viper.GetString(PolicyArg); policyPath != "" {
+		policy, policyErr := web.LoadExposurePolicy(policyPath)
+		if policyErr != nil {
+			logger.Error("crypto load exposure policy error", zap.Error(policyErr))
+			panic(policyErr)
+		}
+		web.SetExposurePolicy(policy)
+	}
 	web.HttpHandlerInit(port)
 }
+
+func newEntropySource(name string, devicePath string) (crypto.EntropySource, error) {
+	logger.Info("crypto entropy source", zap.Any("source", name), zap.Any("device", devicePath))
+	switch name {
+	case "os":
+		return crypto.OSEntropySource{}, nil
+	case "hwrng":
+		return crypto.NewDeviceEntropySource(devicePath), nil
+	case "mixed":
+		return crypto.NewMixedEntropySource(crypto.OSEntropySource{}, crypto.NewDeviceEntropySource(devicePath))
+	default:
+		return nil, errors.Errorf("unknown entropy source %s, must be os, hwrng or mixed", name)
+	}
+}
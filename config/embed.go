@@ -0,0 +1,8 @@
+// Package config embeds the official BIP39 word lists and their manifest.
+// They are the default word lists of the service, a --config directory only overrides them.
+package config
+
+import "embed"
+
+//go:embed manifest.json *.txt
+var WordLists embed.FS
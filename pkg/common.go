@@ -1,14 +1,14 @@
 package common
 
 import (
-	"bufio"
 	"fmt"
+	"github.com/pkg/errors"
+	"github.com/pzhenzhou/crypto-prototype/config"
 	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"io/ioutil"
+	"io/fs"
 	"os"
-	"path"
 	"strings"
 )
 
@@ -16,10 +16,19 @@ type CryptoEnv string
 type Language string
 
 const (
-	EnvPrefix         CryptoEnv = "CRYPTO"
-	RunEnv            CryptoEnv = "RUN_ENV"
-	English           Language  = "english"
-	ChineseSimplified Language  = "chinese_simplified"
+	EnvPrefix          CryptoEnv = "CRYPTO"
+	RunEnv             CryptoEnv = "RUN_ENV"
+	NetworkEnv         CryptoEnv = "NETWORK"
+	English            Language  = "english"
+	ChineseSimplified  Language  = "chinese_simplified"
+	ChineseTraditional Language  = "chinese_traditional"
+	French             Language  = "french"
+	Spanish            Language  = "spanish"
+	Italian            Language  = "italian"
+	Japanese           Language  = "japanese"
+	Korean             Language  = "korean"
+	Czech              Language  = "czech"
+	Portuguese         Language  = "portuguese"
 )
 
 func (c CryptoEnv) String() string {
@@ -27,14 +36,12 @@ func (c CryptoEnv) String() string {
 }
 
 var (
-	EnvSlice        = []CryptoEnv{RunEnv}
-	bitcoinPropose  = []int{44, 49, 84}
-	logger          *zap.Logger
-	supportLanguage = map[Language]bool{
-		English:           true,
-		ChineseSimplified: true,
-	}
-	wordList = map[Language][]string{}
+	EnvSlice       = []CryptoEnv{RunEnv, NetworkEnv}
+	bitcoinPropose = []int{44, 49, 84, 86}
+	logger         *zap.Logger
+	// supportLanguage and wordList are filled from the embedded word lists at init and overridden by LoadWordsList
+	supportLanguage = map[Language]bool{}
+	wordList        = map[Language][]string{}
 )
 
 func GetLogger() *zap.Logger {
@@ -60,6 +67,15 @@ func IsSupportLanguage(input Language) bool {
 	return false
 }
 
+// MnemonicSeparator returns the separator used to join the words of a mnemonic.
+// BIP39 requires Japanese mnemonics to be joined with the ideographic space (U+3000).
+func MnemonicSeparator(language Language) string {
+	if language == Japanese {
+		return "\u3000"
+	}
+	return " "
+}
+
 func GetWordList() map[Language][]string {
 	var res = make(map[Language][]string)
 	for key, value := range wordList {
@@ -80,34 +96,77 @@ func init() {
 		}
 	}
 	initLogger()
+	if loadErr := loadEmbeddedWordsList(); loadErr != nil {
+		fmt.Println("crypto load embedded word lists error cause by", loadErr)
+		os.Exit(-1)
+	}
 }
 
-func LoadWordsList(configPath string) {
-	files, err := ioutil.ReadDir(configPath)
+// LoadWordsList overrides the embedded word lists with the ones of configPath.
+// The manifest of configPath is optional, without it the embedded manifest is used for the files found in configPath.
+// A language that is also embedded must match the embedded SHA-256, a missing file keeps the embedded list.
+// Every list is verified before any of them is used, a single failure keeps the previously loaded lists.
+func LoadWordsList(configPath string) error {
+	embeddedManifest, err := ReadManifest(config.WordLists)
 	if err != nil {
-		fmt.Println("Read ConfigDir Error", err)
-		panic(err)
+		return err
 	}
-	for _, file := range files {
-		if file.IsDir() {
+	configFS := os.DirFS(configPath)
+	manifest, err := ReadManifest(configFS)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.Info("word list manifest not found, use the embedded manifest", zap.Any("configPath", configPath))
+		manifest = embeddedManifest
+	} else if err != nil {
+		return err
+	}
+	loaded := GetWordList()
+	overridden := make(map[Language]bool, len(manifest.WordLists))
+	for _, entry := range manifest.WordLists {
+		if overridden[entry.Language] {
+			return errors.Errorf("word list manifest declares %s more than once", entry.Language)
+		}
+		overridden[entry.Language] = true
+		pinned, embedded := embeddedManifest.Entry(entry.Language)
+		if embedded && !strings.EqualFold(pinned.Sha256, entry.Sha256) {
+			return errors.Errorf("%s word list sha256 %s does not match the embedded %s", entry.Language, entry.Sha256, pinned.Sha256)
+		}
+		words, err := entry.Load(configFS)
+		if embedded && errors.Is(err, fs.ErrNotExist) {
 			continue
 		}
-		fileName := file.Name()
-		filenameWithoutExt := strings.Replace(fileName, path.Ext(fileName), "", -1)
-		if val, ok := supportLanguage[Language(filenameWithoutExt)]; ok && val {
-			filePtr, _ := os.Open(configPath + "/" + fileName)
-			scanner := bufio.NewScanner(filePtr)
-			scanner.Split(bufio.ScanWords)
-			wordBuf := make([]string, 0)
-			for scanner.Scan() {
-				if scanner.Text() == "" {
-					continue
-				}
-				wordBuf = append(wordBuf, strings.TrimSpace(scanner.Text()))
-			}
-			wordList[Language(filenameWithoutExt)] = wordBuf
+		if err != nil {
+			return err
 		}
+		loaded[entry.Language] = words
+	}
+	setWordList(loaded)
+	return nil
+}
+
+func loadEmbeddedWordsList() error {
+	manifest, err := ReadManifest(config.WordLists)
+	if err != nil {
+		return err
+	}
+	loaded := make(map[Language][]string, len(manifest.WordLists))
+	for _, entry := range manifest.WordLists {
+		words, err := entry.Load(config.WordLists)
+		if err != nil {
+			return err
+		}
+		loaded[entry.Language] = words
+	}
+	setWordList(loaded)
+	return nil
+}
+
+func setWordList(loaded map[Language][]string) {
+	support := make(map[Language]bool, len(loaded))
+	for language := range loaded {
+		support[language] = true
 	}
+	wordList = loaded
+	supportLanguage = support
 }
 
 func IsProd() bool {
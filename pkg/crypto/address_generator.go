@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"encoding/hex"
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
@@ -17,8 +18,15 @@ type GenerateArgs string
 const (
 	InputPassword                GenerateArgs = "password"
 	InputMnemonic                GenerateArgs = "mnemonic"
+	InputLanguage                GenerateArgs = "language"
+	InputWordCount               GenerateArgs = "wordCount"
+	InputMnemonicFormat          GenerateArgs = "mnemonicFormat"
 	InputSeed                    GenerateArgs = "Seed"
 	InputPath                    GenerateArgs = "path"
+	InputNetwork                 GenerateArgs = "network"
+	InputScriptType              GenerateArgs = "scriptType"
+	InputIncludeRootKey          GenerateArgs = "includeRootKey"
+	InputSlip132                 GenerateArgs = "slip132"
 	MultiSigNum                  GenerateArgs = "multiSigPair"
 	MultiSigPublicKey            GenerateArgs = "multiSigPublicKeys"
 	HDSegWitAddressGenerator                  = "HDSegWitAddressGenerator"
@@ -32,17 +40,35 @@ var (
 	MultiSigPublicKeyInvalid = errors.New("n-out-of-m MultiSig.invalid public key")
 )
 
+// MnemonicFormat is the scheme of a user-supplied mnemonic.
+type MnemonicFormat string
+
+const (
+	MnemonicBIP39    MnemonicFormat = "bip39"
+	MnemonicElectrum MnemonicFormat = "electrum"
+	MnemonicAezeed   MnemonicFormat = "aezeed"
+)
+
 type MultiSigNumPair struct {
 	N int
 	M int
 }
 
+// Address is a generated address and the keys of its path.
+// PublicKey, PrivateKey and WIF are the child key of the path, the key that controls the address.
+// AccountPublicKey is the xpub of the path without its change and index levels, e.g. m/84'/0'/0'.
+// RootPrivateKey is the master xprv, it is only filled when the request opts in with InputIncludeRootKey.
 type Address struct {
-	Address    string `json:"address"`
-	PublicKey  string `json:"publicKey,omitempty"`
-	PrivateKey string `json:"privateKey,omitempty"`
-	Mnemonic   string `json:"mnemonic,omitempty"`
-	Seed       string `json:"seed,omitempty"`
+	Address          string `json:"address"`
+	PublicKey        string `json:"publicKey,omitempty"`
+	PrivateKey       string `json:"privateKey,omitempty"`
+	Mnemonic         string `json:"mnemonic,omitempty"`
+	Seed             string `json:"seed,omitempty"`
+	Network          string `json:"network,omitempty"`
+	ScriptType       string `json:"scriptType,omitempty"`
+	WIF              string `json:"wif,omitempty"`
+	AccountPublicKey string `json:"accountPublicKey,omitempty"`
+	RootPrivateKey   string `json:"rootPrivateKey,omitempty"`
 }
 
 type AddressGenerator interface {
@@ -53,6 +79,7 @@ func AddGeneratorCaller() map[string]AddressGenerator {
 	return map[string]AddressGenerator{
 		HDSegWitAddressGenerator:     NewHDSegWitAddress(GetSeedGenerator(common.GetWordList())),
 		NofMMultiSigAddressGenerator: MultiSigAddress{},
+		TaprootAddressGenerator:      NewTaprootAddress(GetSeedGenerator(common.GetWordList())),
 	}
 }
 
@@ -79,35 +106,84 @@ func NewHDSegWitAddress(seedGenerator *SeedGenerator) HDSegWitAddress {
 	}
 }
 
-func (h HDSegWitAddress) getMnemonicAndSeed(password string, args map[GenerateArgs]interface{}) (string, []byte, error) {
+func (h HDSegWitAddress) getMnemonicAndSeed(password string, args map[GenerateArgs]interface{}) (string, []byte, ElectrumSeedType, error) {
 	var seed []byte
 	if seedString, ok := args[InputSeed]; ok {
 		if seedBytes, err := hex.DecodeString(seedString.(string)); err != nil {
-			return "", nil, err
+			return "", nil, "", err
 		} else {
 			seed = seedBytes
 		}
-		return "", seed, nil
+		return "", seed, "", nil
 	}
 	logger.Info("Request seed not found")
+	language := common.English
+	if inputLanguage, ok := args[InputLanguage]; ok {
+		language = inputLanguage.(common.Language)
+	}
 	mnemonic := args[InputMnemonic]
 	if inputMnemonic, ok := args[InputMnemonic]; !ok {
 		logger.Info("Request mnemonic not found")
-		newMnemonic, err := h.seedGenerator.NewMnemonic(common.English, Word12)
+		wordCount := Word12
+		if inputWordCount, ok := args[InputWordCount]; ok {
+			wordCount = inputWordCount.(WordCount)
+		}
+		newMnemonic, err := h.seedGenerator.NewMnemonic(language, wordCount)
 		if err != nil {
-			return "", nil, err
+			return "", nil, "", err
 		}
 		mnemonic = newMnemonic
 		seed = h.seedGenerator.NewSeed(newMnemonic, password)
 	} else {
-		seed = h.seedGenerator.NewSeed(inputMnemonic.(string), password)
+		format := MnemonicFormat("")
+		if inputFormat, ok := args[InputMnemonicFormat]; ok {
+			format = inputFormat.(MnemonicFormat)
+		}
+		return h.decodeMnemonic(language, format, inputMnemonic.(string), password)
 	}
-	return mnemonic.(string), seed, nil
+	return mnemonic.(string), seed, "", nil
+}
+
+// decodeMnemonic stretches a user-supplied mnemonic of format into its seed. Without format the mnemonic must be BIP39,
+// Electrum and aezeed phrases are only decoded when format names them: every BIP39 phrase has a 1 in 256 chance to
+// carry an Electrum version prefix, so guessing would silently derive another wallet.
+func (h HDSegWitAddress) decodeMnemonic(language common.Language, format MnemonicFormat, mnemonic string,
+	password string) (string, []byte, ElectrumSeedType, error) {
+	switch format {
+	case "", MnemonicBIP39:
+		if validateErr := h.seedGenerator.ValidateMnemonic(language, mnemonic); validateErr != nil {
+			logger.Warn("HDSegWitAddress invalid mnemonic", zap.Error(validateErr))
+			return "", nil, "", validateErr
+		}
+		return mnemonic, h.seedGenerator.NewSeed(mnemonic, password), "", nil
+	case MnemonicElectrum:
+		seedType, _ := ElectrumSeedVersion(mnemonic)
+		logger.Info("HDSegWitAddress Electrum mnemonic", zap.Any("seedType", seedType))
+		seed, err := NewElectrumSeed(mnemonic, password)
+		return mnemonic, seed, seedType, err
+	case MnemonicAezeed:
+		logger.Info("HDSegWitAddress aezeed mnemonic")
+		aezeedSeed, err := h.seedGenerator.DecodeAezeed(mnemonic, password)
+		if err != nil {
+			return "", nil, "", err
+		}
+		return mnemonic, aezeedSeed.Entropy[:], "", nil
+	}
+	return "", nil, "", errors.Errorf("unknown mnemonic format %s", format)
 }
 
 // Generate Produce HD SegWit address based on the given mnemonic and password
-// If the mnemonic is empty, the method automatically generates a 12-digit English mnemonic
+// If the mnemonic is empty, the method automatically generates a mnemonic of InputWordCount words (12 by default)
+// in InputLanguage (English by default). A user-supplied mnemonic is validated against the word list of InputLanguage
 // If a password is not present, an empty string "" is used instead.
+// The mnemonic is BIP39 unless InputMnemonicFormat says otherwise. An Electrum seed is stretched the Electrum way and
+// derived on the Electrum default path of the change and index of InputPath, see ElectrumPath. A standard seed gives
+// a P2PKH address. An LND aezeed is deciphered with the password and its entropy is the BIP32 seed.
+// InputNetwork selects the network of the address and of the extended keys, DefaultNetwork when it is absent.
+// The script type follows the purpose of InputPath, see ScriptTypeOfPath, or the Electrum seed type,
+// InputScriptType overrides both. The master xprv is only returned when InputIncludeRootKey is true.
+// With InputSlip132 the child and account keys carry the SLIP-132 version of the script type and network, e.g. zpub
+// for P2WPKH on mainnet, see KeyFormatOf. The master key is not bound to a script type and stays xprv or tprv.
 func (h HDSegWitAddress) Generate(args map[GenerateArgs]interface{}) (*Address, error) {
 	if args == nil || len(args) == 0 {
 		return nil, ArgsMustBeNotNull
@@ -120,35 +196,122 @@ func (h HDSegWitAddress) Generate(args map[GenerateArgs]interface{}) (*Address,
 		password = pwd.(string)
 	}
 	path := args[InputPath].(string)
-	mnemonic, seed, err := h.getMnemonicAndSeed(password, args)
+	params, err := networkParamsOf(args)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic, seed, electrumSeedType, err := h.getMnemonicAndSeed(password, args)
 	logger.Info("newMnemonic ", zap.Any("mnemonic", mnemonic))
 	if err != nil {
 		logger.Error("HDSegWitAddress getMnemonicAndSeed Err", zap.Error(err))
 		return nil, err
 	}
-	masterPrivateKey, err := bip32.NewMasterKey(seed)
+	scriptType := ScriptTypeOfPath(path)
+	if electrumSeedType != "" {
+		if path, err = ElectrumPath(electrumSeedType, path); err != nil {
+			return nil, err
+		}
+		scriptType = ScriptP2WPKH
+		if electrumSeedType == ElectrumStandard {
+			scriptType = ScriptP2PKH
+		}
+	}
+	if inputScriptType, ok := args[InputScriptType]; ok {
+		scriptType = inputScriptType.(ScriptType)
+	}
+	if scriptType == ScriptP2TR && electrumSeedType != "" {
+		return nil, TaprootElectrumSeedInvalid
+	}
+	address, err := h.fromSeed(seed, path, scriptType, params)
 	if err != nil {
-		logger.Error("HDSegWitAddress NewMasterKey Err", zap.Error(err))
 		return nil, err
 	}
-	children := strings.Split(path, "/")[1:]
-	bip32Key, err := extractKeyForBIP32(children, masterPrivateKey)
-	witness := btcutil.Hash160(bip32Key.PublicKey().Key)
-	addressHash, err := btcutil.NewAddressWitnessPubKeyHash(witness, &chaincfg.MainNetParams)
+	address.Mnemonic = mnemonic
+	if slip132, ok := args[InputSlip132]; ok && slip132.(bool) {
+		if err := address.convertKeys(KeyFormatOf(scriptType, params)); err != nil {
+			return nil, err
+		}
+	}
+	if includeRootKey, ok := args[InputIncludeRootKey]; ok && includeRootKey.(bool) {
+		masterPrivateKey, err := bip32.NewMasterKey(seed)
+		if err != nil {
+			return nil, err
+		}
+		address.RootPrivateKey = serializeKey(masterPrivateKey, params)
+	}
+	return address, nil
+}
 
+// fromSeed derives the scriptType address of path from seed, without logging the input, so that it can be
+// called for every candidate of a mnemonic recovery.
+func (h HDSegWitAddress) fromSeed(seed []byte, path string, scriptType ScriptType, params *chaincfg.Params) (*Address, error) {
+	_, accountKey, bip32Key, err := deriveKey(seed, path)
 	if err != nil {
-		logger.Error("HDSegWitAddress NewMasterKey Err", zap.Error(err))
+		return nil, err
+	}
+	addressHash, err := scriptAddress(bip32Key.PublicKey().Key, scriptType, params)
+	if err != nil {
+		logger.Error("HDSegWitAddress scriptAddress Err", zap.Any("scriptType", scriptType), zap.Error(err))
+		return nil, err
+	}
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bip32Key.Key)
+	wif, err := btcutil.NewWIF(privateKey, params, true)
+	if err != nil {
+		logger.Error("HDSegWitAddress NewWIF Err", zap.Error(err))
 		return nil, err
 	}
 	return &Address{
-		addressHash.EncodeAddress(),
-		bip32Key.PublicKey().B58Serialize(),
-		masterPrivateKey.B58Serialize(),
-		mnemonic,
-		hex.EncodeToString(seed),
+		Address:          addressHash.EncodeAddress(),
+		PublicKey:        serializeKey(bip32Key.PublicKey(), params),
+		PrivateKey:       serializeKey(bip32Key, params),
+		Seed:             hex.EncodeToString(seed),
+		Network:          params.Name,
+		ScriptType:       string(scriptType),
+		WIF:              wif.String(),
+		AccountPublicKey: serializeKey(accountKey.PublicKey(), params),
 	}, nil
 }
 
+// convertKeys rewrites the child and account extended keys of the address to format.
+func (a *Address) convertKeys(format KeyFormat) error {
+	for _, key := range []*string{&a.PublicKey, &a.PrivateKey, &a.AccountPublicKey} {
+		converted, err := ConvertExtendedKey(*key, format)
+		if err != nil {
+			return err
+		}
+		*key = converted
+	}
+	return nil
+}
+
+// deriveKey returns the master key of seed, the account key of path, the parent of its change and index levels,
+// and the child key of path.
+func deriveKey(seed []byte, path string) (*bip32.Key, *bip32.Key, *bip32.Key, error) {
+	masterPrivateKey, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		logger.Error("HDSegWitAddress NewMasterKey Err", zap.Error(err))
+		return nil, nil, nil, err
+	}
+	children := strings.Split(path, "/")[1:]
+	if len(children) < 2 {
+		return nil, nil, nil, errors.Errorf("path %s has no change and index levels", path)
+	}
+	accountKey := masterPrivateKey
+	if accountLevels := children[:len(children)-2]; len(accountLevels) > 0 {
+		accountKey, err = extractKeyForBIP32(accountLevels, masterPrivateKey)
+		if err != nil {
+			logger.Error("HDSegWitAddress extractKeyForBIP32 Err", zap.Error(err))
+			return nil, nil, nil, err
+		}
+	}
+	bip32Key, err := extractKeyForBIP32(children[len(children)-2:], accountKey)
+	if err != nil {
+		logger.Error("HDSegWitAddress extractKeyForBIP32 Err", zap.Error(err))
+		return nil, nil, nil, err
+	}
+	return masterPrivateKey, accountKey, bip32Key, nil
+}
+
 type MultiSigAddress struct {
 }
 
@@ -188,16 +351,18 @@ func (m MultiSigAddress) Generate(args map[GenerateArgs]interface{}) (*Address,
 	if err != nil {
 		return nil, err
 	}
+	params, err := networkParamsOf(args)
+	if err != nil {
+		return nil, err
+	}
 	redeemHash := btcutil.Hash160(script)
-	address, err := btcutil.NewAddressScriptHashFromHash(redeemHash, &chaincfg.MainNetParams)
+	address, err := btcutil.NewAddressScriptHashFromHash(redeemHash, params)
 	if err != nil {
 		return nil, err
 	}
 	return &Address{
-		address.EncodeAddress(),
-		"",
-		"",
-		"",
-		"",
+		Address:    address.EncodeAddress(),
+		Network:    params.Name,
+		ScriptType: string(ScriptP2SHMultiSig),
 	}, nil
 }
@@ -3,6 +3,7 @@ package crypto
 import (
 	common "github.com/pzhenzhou/crypto-prototype/pkg"
 	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip32"
 	"regexp"
 	"testing"
 )
@@ -35,6 +36,19 @@ func TestHDSegWitAddress_Generate(t *testing.T) {
 	assert.Nil(t, nil, err)
 }
 
+func TestHDSegWitAddress_Generate_InvalidMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	addressGenerator := NewHDSegWitAddress(testSeedGenerator)
+	args := map[GenerateArgs]interface{}{
+		InputPath:     "m/44'/0'/0'/0/0",
+		InputMnemonic: "legal winner thank year wave sausage worth useful legal winner thank thank",
+	}
+	address, err := addressGenerator.Generate(args)
+	assert.Nil(t, address)
+	var checksumErr *ChecksumMismatchError
+	assert.ErrorAs(t, err, &checksumErr)
+}
+
 func TestMultiSigAddress_Generate_IllegalArgs(t *testing.T) {
 	multiSigAddress := MultiSigAddress{}
 	args := map[GenerateArgs]interface{}{
@@ -63,3 +77,35 @@ func TestMultiSigAddress_Generate(t *testing.T) {
 	assert.True(t, true, isMatch)
 	assert.Nil(t, nil, err)
 }
+
+// BIP84 and BIP86 test vectors of "abandon ... about"
+func TestHDSegWitAddress_Generate_ChildKey(t *testing.T) {
+	addressGenerator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	args := map[GenerateArgs]interface{}{
+		InputPath:     "m/84'/0'/0'/0/0",
+		InputMnemonic: networkTestMnemonic,
+	}
+	address, err := addressGenerator.Generate(args)
+	assert.NoError(t, err)
+	assert.Equal(t, "KyZpNDKnfs94vbrwhJneDi77V6jF64PWPF8x5cdJb8ifgg2DUc9d", address.WIF)
+	assert.Equal(t, "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V", address.AccountPublicKey)
+	childKey, err := bip32.B58Deserialize(address.PrivateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(5), childKey.Depth)
+	assert.True(t, childKey.IsPrivate)
+	assert.Equal(t, address.PublicKey, childKey.PublicKey().B58Serialize())
+	// the root key is only returned on request
+	assert.Empty(t, address.RootPrivateKey)
+
+	args[InputIncludeRootKey] = true
+	address, err = addressGenerator.Generate(args)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu", address.RootPrivateKey)
+
+	address, err = addressGenerator.Generate(map[GenerateArgs]interface{}{
+		InputPath:     "m/86'/0'/0'/0/0",
+		InputMnemonic: networkTestMnemonic,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ", address.AccountPublicKey)
+}
@@ -0,0 +1,169 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"github.com/Yawning/aez"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+	"hash/crc32"
+	"strings"
+	"time"
+)
+
+// LND aezeed cipher seed, https://github.com/lightningnetwork/lnd/tree/master/aezeed
+// The 24 English words encode 33 bytes: version (1) || AEZ ciphertext (23) || salt (5) || CRC32C checksum (4).
+// The plaintext is internal version (1) || birthday (2, days since the genesis block) || entropy (16),
+// encrypted with an scrypt key of the password ("aezeed" when empty) and the salt.
+
+const (
+	AezeedVersion          = 0
+	AezeedEntropySize      = 16
+	AezeedWordCount        = 24
+	aezeedSaltSize         = 5
+	aezeedChecksumSize     = 4
+	aezeedDecipheredSize   = 19
+	aezeedCipherExpansion  = 4
+	aezeedEncipheredSize   = 33
+	aezeedDefaultPassword  = "aezeed"
+	aezeedScryptR          = 8
+	aezeedScryptP          = 1
+	aezeedKeyLen           = 32
+	aezeedCipherTextOffset = 1
+	aezeedSaltOffset       = aezeedCipherTextOffset + aezeedDecipheredSize + aezeedCipherExpansion
+	aezeedChecksumOffset   = aezeedSaltOffset + aezeedSaltSize
+)
+
+var (
+	// aezeedScryptN the scrypt cost of the cipher seed key, a variable so that the tests can use the N = 16 of lnd
+	aezeedScryptN = 32768
+
+	AezeedWordCountInvalid = errors.Errorf("aezeed mnemonic must be %d words", AezeedWordCount)
+	AezeedVersionInvalid   = errors.New("aezeed mnemonic version is not supported")
+	AezeedChecksumInvalid  = errors.New("aezeed mnemonic checksum mismatch")
+	AezeedPasswordInvalid  = errors.New("aezeed mnemonic password is invalid")
+
+	// AezeedGenesisDate is the time of the bitcoin genesis block, the zero of the aezeed birthday.
+	AezeedGenesisDate = time.Unix(1231006505, 0)
+	aezeedCrcTable    = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// AezeedSeed is a deciphered aezeed. Entropy is the BIP32 seed of the LND wallet.
+type AezeedSeed struct {
+	InternalVersion uint8
+	Birthday        uint16
+	Entropy         [AezeedEntropySize]byte
+	salt            [aezeedSaltSize]byte
+}
+
+// BirthdayTime returns the day the seed was created.
+func (s AezeedSeed) BirthdayTime() time.Time {
+	return AezeedGenesisDate.Add(time.Duration(s.Birthday) * 24 * time.Hour)
+}
+
+// NewAezeed creates an aezeed mnemonic of birthday, its entropy and salt are read from the generator entropy source.
+func (g *SeedGenerator) NewAezeed(password string, birthday time.Time) (string, *AezeedSeed, error) {
+	seed := &AezeedSeed{
+		InternalVersion: AezeedVersion,
+		Birthday:        uint16(birthday.Sub(AezeedGenesisDate) / (24 * time.Hour)),
+	}
+	if _, err := g.entropySource.Read(seed.Entropy[:]); err != nil {
+		return "", nil, err
+	}
+	if _, err := g.entropySource.Read(seed.salt[:]); err != nil {
+		return "", nil, err
+	}
+	mnemonic, err := g.encipherAezeed(seed, password)
+	if err != nil {
+		return "", nil, err
+	}
+	return mnemonic, seed, nil
+}
+
+// ValidateAezeed checks the words, the version and the checksum of an aezeed mnemonic, the password is not needed.
+func (g *SeedGenerator) ValidateAezeed(mnemonic string) error {
+	_, err := g.aezeedBytes(mnemonic)
+	return err
+}
+
+// DecodeAezeed deciphers an aezeed mnemonic with password, a wrong password returns AezeedPasswordInvalid.
+func (g *SeedGenerator) DecodeAezeed(mnemonic string, password string) (*AezeedSeed, error) {
+	enciphered, err := g.aezeedBytes(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	seed := &AezeedSeed{}
+	copy(seed.salt[:], enciphered[aezeedSaltOffset:aezeedChecksumOffset])
+	key, err := aezeedKey(password, seed.salt)
+	if err != nil {
+		return nil, err
+	}
+	plainText, ok := aez.Decrypt(key, nil, [][]byte{aezeedAD(seed.salt)}, aezeedCipherExpansion,
+		enciphered[aezeedCipherTextOffset:aezeedSaltOffset], nil)
+	if !ok {
+		return nil, AezeedPasswordInvalid
+	}
+	seed.InternalVersion = plainText[0]
+	seed.Birthday = binary.BigEndian.Uint16(plainText[1:3])
+	copy(seed.Entropy[:], plainText[3:])
+	return seed, nil
+}
+
+func (g *SeedGenerator) encipherAezeed(seed *AezeedSeed, password string) (string, error) {
+	key, err := aezeedKey(password, seed.salt)
+	if err != nil {
+		return "", err
+	}
+	plainText := make([]byte, aezeedDecipheredSize)
+	plainText[0] = seed.InternalVersion
+	binary.BigEndian.PutUint16(plainText[1:3], seed.Birthday)
+	copy(plainText[3:], seed.Entropy[:])
+	cipherText := aez.Encrypt(key, nil, [][]byte{aezeedAD(seed.salt)}, aezeedCipherExpansion, plainText, nil)
+
+	enciphered := make([]byte, aezeedEncipheredSize)
+	enciphered[0] = AezeedVersion
+	copy(enciphered[aezeedCipherTextOffset:], cipherText)
+	copy(enciphered[aezeedSaltOffset:], seed.salt[:])
+	binary.BigEndian.PutUint32(enciphered[aezeedChecksumOffset:], crc32.Checksum(enciphered[:aezeedChecksumOffset], aezeedCrcTable))
+
+	words, err := mnemonic(bytesToInts(enciphered, AezeedWordCount), g.bip39Word[common.English])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(words, common.MnemonicSeparator(common.English)), nil
+}
+
+// aezeedBytes decodes the 24 words into the 33 enciphered bytes and checks the version and the checksum.
+func (g *SeedGenerator) aezeedBytes(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(words) != AezeedWordCount {
+		return nil, AezeedWordCountInvalid
+	}
+	indexes, err := g.wordIndexes(common.English, words)
+	if err != nil {
+		return nil, err
+	}
+	// the 24 words are exactly the 264 bits of the 33 enciphered bytes
+	enciphered := intsToBytes(indexes)
+	if enciphered[0] != AezeedVersion {
+		return nil, AezeedVersionInvalid
+	}
+	checksum := binary.BigEndian.Uint32(enciphered[aezeedChecksumOffset:])
+	if checksum != crc32.Checksum(enciphered[:aezeedChecksumOffset], aezeedCrcTable) {
+		return nil, AezeedChecksumInvalid
+	}
+	return enciphered, nil
+}
+
+func aezeedKey(password string, salt [aezeedSaltSize]byte) ([]byte, error) {
+	if password == "" {
+		password = aezeedDefaultPassword
+	}
+	return scrypt.Key([]byte(password), salt[:], aezeedScryptN, aezeedScryptR, aezeedScryptP, aezeedKeyLen)
+}
+
+// aezeedAD is the associated data of the AEZ encryption, version || salt.
+func aezeedAD(salt [aezeedSaltSize]byte) []byte {
+	return append([]byte{AezeedVersion}, salt[:]...)
+}
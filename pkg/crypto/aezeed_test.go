@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/chaincfg"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	aezeedTestEntropy = [AezeedEntropySize]byte{
+		0x81, 0xb6, 0x37, 0xd8, 0x63, 0x59, 0xe6, 0x96,
+		0x0d, 0xe7, 0x95, 0xe4, 0x1e, 0x0b, 0x4c, 0xfd,
+	}
+	// "salt1"
+	aezeedTestSalt = [aezeedSaltSize]byte{0x73, 0x61, 0x6c, 0x74, 0x31}
+)
+
+// lnd aezeed/cipherseed_test.go version 0 vectors, enciphered with the scrypt N = 16 of the lnd test
+var aezeedDict = []struct {
+	password string
+	birthday time.Time
+	mnemonic string
+	days     uint16
+}{
+	{
+		"",
+		AezeedGenesisDate,
+		"ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+		0,
+	},
+	{
+		"!very_safe_55345_password*",
+		time.Unix(1521799345, 0),
+		"able tree stool crush transfer cloud cross three profit outside hen citizen plate ride require leg siren drum success suggest drink require fiscal upgrade",
+		3365,
+	},
+}
+
+// withLndScryptN runs test with the scrypt N of the lnd vectors.
+func withLndScryptN(test func()) {
+	defaultScryptN := aezeedScryptN
+	aezeedScryptN = 16
+	defer func() { aezeedScryptN = defaultScryptN }()
+	test()
+}
+
+func TestAezeed(t *testing.T) {
+	withLndScryptN(func() { testAezeedVectors(t) })
+}
+
+func testAezeedVectors(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for _, vector := range aezeedDict {
+		seed := &AezeedSeed{
+			InternalVersion: AezeedVersion,
+			Birthday:        uint16(vector.birthday.Sub(AezeedGenesisDate) / (24 * time.Hour)),
+			Entropy:         aezeedTestEntropy,
+			salt:            aezeedTestSalt,
+		}
+		assert.Equal(t, vector.days, seed.Birthday)
+		mnemonic, err := testSeedGenerator.encipherAezeed(seed, vector.password)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.mnemonic, mnemonic)
+
+		assert.NoError(t, testSeedGenerator.ValidateAezeed(vector.mnemonic))
+		decoded, err := testSeedGenerator.DecodeAezeed(vector.mnemonic, vector.password)
+		assert.NoError(t, err)
+		assert.Equal(t, aezeedTestEntropy, decoded.Entropy)
+		assert.Equal(t, vector.days, decoded.Birthday)
+		assert.Equal(t, uint8(AezeedVersion), decoded.InternalVersion)
+	}
+}
+
+func TestAezeedInvalid(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	withLndScryptN(func() {
+		_, err := testSeedGenerator.DecodeAezeed(aezeedDict[1].mnemonic, "wrong password")
+		assert.Equal(t, AezeedPasswordInvalid, err)
+	})
+
+	words := strings.Fields(aezeedDict[0].mnemonic)
+	words[3] = "zoo"
+	assert.Equal(t, AezeedChecksumInvalid, testSeedGenerator.ValidateAezeed(strings.Join(words, " ")))
+	words[0] = "zoo"
+	assert.Equal(t, AezeedVersionInvalid, testSeedGenerator.ValidateAezeed(strings.Join(words, " ")))
+	assert.Equal(t, AezeedWordCountInvalid, testSeedGenerator.ValidateAezeed(strings.Join(words[:12], " ")))
+}
+
+func TestNewAezeed(t *testing.T) {
+	testSeedGenerator := NewSeedGenerator(common.GetWordList(), NewDeterministicEntropySource([]byte("aezeed")))
+	birthday := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	mnemonic, seed, err := testSeedGenerator.NewAezeed("password", birthday)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Fields(mnemonic), AezeedWordCount)
+	decoded, err := testSeedGenerator.DecodeAezeed(mnemonic, "password")
+	assert.NoError(t, err)
+	assert.Equal(t, seed.Entropy, decoded.Entropy)
+	assert.Equal(t, seed.Birthday, decoded.Birthday)
+	// the birthday counts whole days from the genesis block time of day
+	assert.True(t, !decoded.BirthdayTime().After(birthday) && birthday.Sub(decoded.BirthdayTime()) < 24*time.Hour)
+}
+
+func TestHDSegWitAddress_Generate_Aezeed(t *testing.T) {
+	withLndScryptN(func() { testGenerateAezeed(t) })
+}
+
+func testGenerateAezeed(t *testing.T) {
+	generator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	address, err := generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       aezeedDict[1].mnemonic,
+		InputPassword:       aezeedDict[1].password,
+		InputMnemonicFormat: MnemonicAezeed,
+		InputPath:           "m/84'/0'/0'/0/0",
+	})
+	assert.NoError(t, err)
+	expected, err := generator.fromSeed(aezeedTestEntropy[:], "m/84'/0'/0'/0/0", ScriptP2WPKH, &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Address, address.Address)
+	assert.Equal(t, hex.EncodeToString(aezeedTestEntropy[:]), address.Seed)
+
+	// without the format the phrase must be BIP39
+	_, err = generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic: aezeedDict[0].mnemonic,
+		InputPath:     "m/84'/0'/0'/0/0",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,167 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/tyler-smith/go-bip32"
+	"strconv"
+	"strings"
+)
+
+// BIP85 deterministic entropy from BIP32 keychains, https://github.com/bitcoin/bips/blob/master/bip-0085.mediawiki
+// The entropy of a path is HMAC-SHA512(key "bip-entropy-from-k", private key of m/83696968'/app'/.../index').
+
+type Bip85App int
+
+const (
+	Bip85AppBIP39 Bip85App = 39
+	Bip85AppWIF   Bip85App = 2
+	Bip85AppXPRV  Bip85App = 32
+	Bip85AppHex   Bip85App = 128169
+	// Bip85AppPWDBase64 PWD BASE64, a base64 password
+	Bip85AppPWDBase64 Bip85App = 707764
+
+	bip85Purpose    = "83696968'"
+	bip85HmacKey    = "bip-entropy-from-k"
+	bip85MinHexSize = 16
+	bip85MaxHexSize = 64
+	bip85MinPwdLen  = 20
+	bip85MaxPwdLen  = 86
+)
+
+var (
+	Bip85AppInvalid     = errors.New("BIP85 application must be 39, 2, 32, 128169 or 707764")
+	Bip85HexSizeInvalid = errors.Errorf("BIP85 hex entropy must be between %d and %d bytes", bip85MinHexSize, bip85MaxHexSize)
+	Bip85MasterInvalid  = errors.New("BIP85 master key must be a private key")
+	Bip85PwdLenInvalid  = errors.Errorf("BIP85 base64 password must be between %d and %d characters", bip85MinPwdLen, bip85MaxPwdLen)
+
+	// bip85LanguageCode the language codes of the BIP39 application
+	bip85LanguageCode = map[common.Language]int{
+		common.English:            0,
+		common.Japanese:           1,
+		common.Korean:             2,
+		common.Spanish:            3,
+		common.ChineseSimplified:  4,
+		common.ChineseTraditional: 5,
+		common.French:             6,
+		common.Italian:            7,
+		common.Czech:              8,
+		common.Portuguese:         9,
+	}
+)
+
+// Bip85Entropy returns the 64 bytes entropy of path, a hardened path below m/83696968'.
+func Bip85Entropy(master *bip32.Key, path string) ([]byte, error) {
+	if !master.IsPrivate {
+		return nil, Bip85MasterInvalid
+	}
+	children := strings.Split(path, "/")
+	if len(children) < 3 || children[0] != "m" || children[1] != bip85Purpose {
+		return nil, errors.Errorf("BIP85 invalid path %s", path)
+	}
+	for _, index := range children[1:] {
+		// every BIP85 index is hardened, so below 2^31 before the hardened offset
+		if _, err := strconv.ParseUint(strings.TrimSuffix(index, "'"), 10, 31); err != nil || !strings.HasSuffix(index, "'") {
+			return nil, errors.Errorf("BIP85 invalid path %s", path)
+		}
+	}
+	child, err := extractKeyForBIP32(children[1:], master)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, []byte(bip85HmacKey))
+	mac.Write(child.Key)
+	return mac.Sum(nil), nil
+}
+
+// Bip85Mnemonic derives the count words child mnemonic of index in language, m/83696968'/39'/language'/count'/index'.
+func (g *SeedGenerator) Bip85Mnemonic(master *bip32.Key, input common.Language, count WordCount, index uint32) (string, error) {
+	languageCode, ok := bip85LanguageCode[input]
+	if !ok || !common.IsSupportLanguage(input) {
+		return "", unSupportLanguageError()
+	}
+	seedLen, ok := mnemonicLen[count]
+	if !ok {
+		return "", unSupportWordLenError
+	}
+	entropy, err := Bip85Entropy(master, bip85Path(Bip85AppBIP39, languageCode, int(count), int(index)))
+	if err != nil {
+		return "", err
+	}
+	return g.EntropyToMnemonic(entropy[:seedLen/8], input)
+}
+
+// Bip85WIF derives the child private key of index as a compressed mainnet WIF, m/83696968'/2'/index'.
+func Bip85WIF(master *bip32.Key, index uint32) (string, error) {
+	entropy, err := Bip85Entropy(master, bip85Path(Bip85AppWIF, int(index)))
+	if err != nil {
+		return "", err
+	}
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), entropy[:32])
+	wif, err := btcutil.NewWIF(privateKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		return "", err
+	}
+	return wif.String(), nil
+}
+
+// Bip85XPRV derives the child master xprv of index, m/83696968'/32'/index'.
+// The first 32 bytes of the entropy are the chain code and the last 32 bytes the private key.
+func Bip85XPRV(master *bip32.Key, index uint32) (string, error) {
+	entropy, err := Bip85Entropy(master, bip85Path(Bip85AppXPRV, int(index)))
+	if err != nil {
+		return "", err
+	}
+	child := &bip32.Key{
+		Version:     bip32.PrivateWalletVersion,
+		Depth:       0,
+		ChildNumber: []byte{0x00, 0x00, 0x00, 0x00},
+		FingerPrint: []byte{0x00, 0x00, 0x00, 0x00},
+		ChainCode:   entropy[:32],
+		Key:         entropy[32:],
+		IsPrivate:   true,
+	}
+	return child.B58Serialize(), nil
+}
+
+// Bip85Hex derives size bytes (16 to 64) of hex entropy of index, m/83696968'/128169'/size'/index'.
+func Bip85Hex(master *bip32.Key, size int, index uint32) (string, error) {
+	if size < bip85MinHexSize || size > bip85MaxHexSize {
+		return "", Bip85HexSizeInvalid
+	}
+	entropy, err := Bip85Entropy(master, bip85Path(Bip85AppHex, size, int(index)))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(entropy[:size]), nil
+}
+
+// Bip85PasswordBase64 derives a length characters (20 to 86) base64 password of index, m/83696968'/707764'/length'/index'.
+// The password is the padded standard base64 of the 64 bytes entropy truncated to length.
+func Bip85PasswordBase64(master *bip32.Key, length int, index uint32) (string, error) {
+	if length < bip85MinPwdLen || length > bip85MaxPwdLen {
+		return "", Bip85PwdLenInvalid
+	}
+	entropy, err := Bip85Entropy(master, bip85Path(Bip85AppPWDBase64, length, int(index)))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(entropy)[:length], nil
+}
+
+// bip85Path builds m/83696968'/app'/indexes'..., every index hardened.
+func bip85Path(app Bip85App, indexes ...int) string {
+	path := fmt.Sprintf("m/%s/%d'", bip85Purpose, app)
+	for _, index := range indexes {
+		path += fmt.Sprintf("/%d'", index)
+	}
+	return path
+}
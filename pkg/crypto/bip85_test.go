@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"encoding/hex"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip32"
+	"testing"
+)
+
+// BIP85 test vectors
+const bip85TestMaster = "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb"
+
+func TestBip85Entropy(t *testing.T) {
+	master, err := bip32.B58Deserialize(bip85TestMaster)
+	assert.NoError(t, err)
+	for path, expected := range map[string]string{
+		"m/83696968'/0'/0'": "efecfbccffea313214232d29e71563d941229afb4338c21f9517c41aaa0d16f00b83d2a09ef747e7a64e8e2bd5a14869e693da66ce94ac2da570ab7ee48618f7",
+		"m/83696968'/0'/1'": "70c6e3e8ebee8dc4c0dbba66076819bb8c09672527c4277ca8729532ad711872218f826919f6b67218adde99018a6df9095ab2b58d803b5b93ec9802085a690e",
+	} {
+		entropy, err := Bip85Entropy(master, path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(entropy))
+	}
+	for _, path := range []string{"m/44'/0'/0'", "m/83696968'/0'/0", "m/83696968'", "m/83696968'/0'/2147483648'"} {
+		_, err = Bip85Entropy(master, path)
+		assert.Error(t, err, path)
+	}
+	_, err = Bip85Entropy(master.PublicKey(), "m/83696968'/0'/0'")
+	assert.Equal(t, Bip85MasterInvalid, err)
+}
+
+func TestBip85Applications(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	master, err := bip32.B58Deserialize(bip85TestMaster)
+	assert.NoError(t, err)
+	for count, expected := range map[WordCount]string{
+		Word12: "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose",
+		Word18: "near account window bike charge season chef number sketch tomorrow excuse sniff circle vital hockey outdoor supply token",
+		Word24: "puppy ocean match cereal symbol another shed magic wrap hammer bulb intact gadget divorce twin tonight reason outdoor destroy simple truth cigar social volcano",
+	} {
+		mnemonic, err := testSeedGenerator.Bip85Mnemonic(master, common.English, count, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mnemonic)
+	}
+	for _, language := range common.SupportLanguageSlice() {
+		mnemonic, err := testSeedGenerator.Bip85Mnemonic(master, language, Word12, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(language, mnemonic))
+	}
+
+	wif, err := Bip85WIF(master, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kzyv4uF39d4Jrw2W7UryTHwZr1zQVNk4dAFyqE6BuMrMh1Za7uhp", wif)
+
+	xprv, err := Bip85XPRV(master, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K2srSbCSg4m4kLvPMzcWydgmKEnMmoZUurYuBuYG46c6P71UGXMzmriLzCCBvKQWBUv3vPB3m1SATMhp3uEjXHJ42jFg7myX", xprv)
+
+	hexEntropy, err := Bip85Hex(master, 64, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "492db4698cf3b73a5a24998aa3e9d7fa96275d85724a91e71aa2d645442f878555d078fd1f1f67e368976f04137b1f7a0d19232136ca50c44614af72b5582a5c", hexEntropy)
+	_, err = Bip85Hex(master, 15, 0)
+	assert.Equal(t, Bip85HexSizeInvalid, err)
+
+	password, err := Bip85PasswordBase64(master, 21, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "dKLoepugzdVJvdL56ogNV", password)
+	_, err = Bip85PasswordBase64(master, 87, 0)
+	assert.Equal(t, Bip85PwdLenInvalid, err)
+}
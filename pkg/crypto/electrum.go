@@ -0,0 +1,157 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// ElectrumSeedType is the version of an Electrum seed, the prefix of HMAC-SHA512("Seed version", phrase) in hex.
+// Electrum seeds carry no BIP39 checksum and are stretched with the "electrum" salt instead of "mnemonic".
+// https://electrum.readthedocs.io/en/latest/seedphrase.html
+type ElectrumSeedType string
+
+const (
+	ElectrumStandard ElectrumSeedType = "standard"
+	ElectrumSegwit   ElectrumSeedType = "segwit"
+
+	electrumVersionKey  = "Seed version"
+	electrumSalt        = "electrum"
+	electrumEntropyBits = 132
+)
+
+var (
+	ElectrumSeedTypeInvalid = errors.New("Electrum seed type must be standard or segwit")
+	NotElectrumSeed         = errors.New("Mnemonic is not an Electrum standard or segwit seed")
+	ElectrumPathInvalid     = errors.New("Electrum seeds need a path with the change and the address index")
+	electrumSeedPrefix      = map[ElectrumSeedType]string{
+		ElectrumStandard: "01",
+		ElectrumSegwit:   "100",
+	}
+	// electrumCJKRanges the scripts written without spaces, Electrum removes the whitespace between their characters.
+	electrumCJKRanges = &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x1100, Hi: 0x11ff, Stride: 1},
+			{Lo: 0x2e80, Hi: 0x2fdf, Stride: 1},
+			{Lo: 0x3000, Hi: 0x31ff, Stride: 1},
+			{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+			{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+			{Lo: 0xa960, Hi: 0xa97f, Stride: 1},
+			{Lo: 0xac00, Hi: 0xd7ff, Stride: 1},
+			{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+			{Lo: 0xff00, Hi: 0xffef, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x20000, Hi: 0x2fa1f, Stride: 1},
+		},
+	}
+)
+
+// NewElectrumMnemonic generates a 12 words Electrum seed of seedType from the generator entropy source.
+// As Electrum does, a 132 bits number is incremented until its base 2048 encoding has the version prefix of seedType,
+// phrases that are also valid BIP39 mnemonics are skipped so that the seed format is never ambiguous.
+func (g *SeedGenerator) NewElectrumMnemonic(input common.Language, seedType ElectrumSeedType) (string, error) {
+	if !common.IsSupportLanguage(input) {
+		return "", unSupportLanguageError()
+	}
+	if _, ok := electrumSeedPrefix[seedType]; !ok {
+		return "", ElectrumSeedTypeInvalid
+	}
+	entropyBytes := make([]byte, (electrumEntropyBits+7)/8)
+	minimum := new(big.Int).Lsh(big.NewInt(1), electrumEntropyBits-11)
+	entropy := new(big.Int)
+	for entropy.Cmp(minimum) < 0 {
+		if _, err := g.entropySource.Read(entropyBytes); err != nil {
+			return "", err
+		}
+		entropyBytes[0] &= 0xff >> uint(len(entropyBytes)*8-electrumEntropyBits)
+		entropy.SetBytes(entropyBytes)
+	}
+	for {
+		entropy.Add(entropy, big.NewInt(1))
+		mnemonic := g.electrumEncode(input, entropy)
+		if g.ValidateMnemonic(input, mnemonic) == nil {
+			continue
+		}
+		if detected, ok := ElectrumSeedVersion(mnemonic); ok && detected == seedType {
+			return mnemonic, nil
+		}
+	}
+}
+
+// ElectrumSeedVersion returns the Electrum seed type of mnemonic, false when mnemonic is not a standard or segwit seed.
+// The version only depends on the normalized phrase, so any word list is accepted.
+func ElectrumSeedVersion(mnemonic string) (ElectrumSeedType, bool) {
+	mac := hmac.New(sha512.New, []byte(electrumVersionKey))
+	mac.Write([]byte(electrumNormalize(mnemonic)))
+	version := hex.EncodeToString(mac.Sum(nil))
+	for seedType, prefix := range electrumSeedPrefix {
+		if strings.HasPrefix(version, prefix) {
+			return seedType, true
+		}
+	}
+	return "", false
+}
+
+// NewElectrumSeed stretches an Electrum mnemonic into a 64 bytes seed with PBKDF2-HMAC-SHA512,
+// the salt is "electrum"+password and both the mnemonic and the password are normalized as Electrum does.
+func NewElectrumSeed(mnemonic string, password string) ([]byte, error) {
+	if _, ok := ElectrumSeedVersion(mnemonic); !ok {
+		return nil, NotElectrumSeed
+	}
+	return pbkdf2.Key([]byte(electrumNormalize(mnemonic)), []byte(electrumSalt+electrumNormalize(password)), 2048, 64, sha512.New), nil
+}
+
+// ElectrumPath maps the change and the address index of a BIP44 style path onto the Electrum default derivation,
+// m/<change>/<index> for standard seeds and m/0'/<change>/<index> for segwit seeds.
+// The path must have at least the change and the index after m.
+func ElectrumPath(seedType ElectrumSeedType, path string) (string, error) {
+	children := strings.Split(path, "/")
+	if len(children) < 3 {
+		return "", ElectrumPathInvalid
+	}
+	suffix := strings.Join(children[len(children)-2:], "/")
+	if seedType == ElectrumSegwit {
+		return "m/0'/" + suffix, nil
+	}
+	return "m/" + suffix, nil
+}
+
+// electrumEncode writes entropy in base 2048, least significant word first.
+func (g *SeedGenerator) electrumEncode(input common.Language, entropy *big.Int) string {
+	words := make([]string, 0, electrumEntropyBits/11)
+	value := new(big.Int).Set(entropy)
+	radix := big.NewInt(int64(len(g.bip39Word[input])))
+	index := new(big.Int)
+	for value.Sign() > 0 {
+		value.DivMod(value, radix, index)
+		words = append(words, g.bip39Word[input][index.Int64()])
+	}
+	return strings.Join(words, common.MnemonicSeparator(input))
+}
+
+// electrumNormalize is NFKD, lower case, without accents, with single spaces and without the spaces between CJK characters.
+func electrumNormalize(text string) string {
+	runes := make([]rune, 0, len(text))
+	for _, char := range strings.ToLower(norm.NFKD.String(text)) {
+		if !unicode.Is(unicode.Mn, char) {
+			runes = append(runes, char)
+		}
+	}
+	runes = []rune(strings.Join(strings.Fields(string(runes)), " "))
+	normalized := make([]rune, 0, len(runes))
+	for i, char := range runes {
+		if char == ' ' && unicode.Is(electrumCJKRanges, runes[i-1]) && unicode.Is(electrumCJKRanges, runes[i+1]) {
+			continue
+		}
+		normalized = append(normalized, char)
+	}
+	return string(normalized)
+}
@@ -0,0 +1,171 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/chaincfg"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Electrum lib/tests/test_mnemonic.py
+var electrumSeedDict = []struct {
+	mnemonic string
+	password string
+	seedType ElectrumSeedType
+	seed     string
+}{
+	{
+		"wild father tree among universe such mobile favorite target dynamic credit identify",
+		"",
+		ElectrumSegwit,
+		"aac2a6302e48577ab4b46f23dbae0774e2e62c796f797d0a1b5faeb528301e3064342dafb79069e7c4c6b8c38ae11d7a973bec0d4f70626f8cc5184a8d0b0756",
+	},
+	{
+		"wild father tree among universe such mobile favorite target dynamic credit identify",
+		"Did you ever hear the tragedy of Darth Plagueis the Wise?",
+		ElectrumSegwit,
+		"4aa29f2aeb0127efb55138ab9e7be83b36750358751906f86c662b21a1ea1370f949e6d1a12fa56d3d93cadda93038c76ac8118597364e46f5156fde6183c82f",
+	},
+	{
+		"なのか ひろい しなん まなぶ つぶす さがす おしゃれ かわく おいかける けさき かいとう さたん",
+		"",
+		ElectrumStandard,
+		"d3eaf0e44ddae3a5769cb08a26918e8b308258bcb057bb704c6f69713245c0b35cb92c03df9c9ece5eff826091b4e74041e010b701d44d610976ce8bfb66a8ad",
+	},
+}
+
+func TestElectrumSeed(t *testing.T) {
+	for _, vector := range electrumSeedDict {
+		seedType, ok := ElectrumSeedVersion(vector.mnemonic)
+		assert.True(t, ok)
+		assert.Equal(t, vector.seedType, seedType)
+		seed, err := NewElectrumSeed(vector.mnemonic, vector.password)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.seed, hex.EncodeToString(seed))
+	}
+	// the normalization ignores case, accents and extra whitespace
+	seed, err := NewElectrumSeed("  Wild father TREE among universe such mobile favorite target dynamic credit identify ", "")
+	assert.NoError(t, err)
+	assert.Equal(t, electrumSeedDict[0].seed, hex.EncodeToString(seed))
+
+	_, ok := ElectrumSeedVersion("legal winner thank year wave sausage worth useful legal winner thank yellow")
+	assert.False(t, ok)
+	_, err = NewElectrumSeed("legal winner thank year wave sausage worth useful legal winner thank yellow", "")
+	assert.Equal(t, NotElectrumSeed, err)
+}
+
+func TestNewElectrumMnemonic(t *testing.T) {
+	testSeedGenerator := NewSeedGenerator(common.GetWordList(), NewDeterministicEntropySource([]byte("electrum")))
+	for _, seedType := range []ElectrumSeedType{ElectrumStandard, ElectrumSegwit} {
+		mnemonic, err := testSeedGenerator.NewElectrumMnemonic(common.English, seedType)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), 12)
+		detected, ok := ElectrumSeedVersion(mnemonic)
+		assert.True(t, ok)
+		assert.Equal(t, seedType, detected)
+		assert.Error(t, testSeedGenerator.ValidateMnemonic(common.English, mnemonic))
+	}
+	_, err := testSeedGenerator.NewElectrumMnemonic(common.English, "2fa")
+	assert.Equal(t, ElectrumSeedTypeInvalid, err)
+}
+
+func TestHDSegWitAddress_Generate_Electrum(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	generator := NewHDSegWitAddress(testSeedGenerator)
+
+	segwit := electrumSeedDict[0]
+	address, err := generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       segwit.mnemonic,
+		InputMnemonicFormat: MnemonicElectrum,
+		InputPath:           "m/84'/0'/0'/1/3",
+	})
+	assert.NoError(t, err)
+	seed, _ := hex.DecodeString(segwit.seed)
+	expected, err := generator.fromSeed(seed, "m/0'/1/3", ScriptP2WPKH, &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Address, address.Address)
+	assert.True(t, strings.HasPrefix(address.Address, "bc1q"))
+	assert.Equal(t, segwit.mnemonic, address.Mnemonic)
+	assert.Equal(t, segwit.seed, address.Seed)
+
+	standard := electrumSeedDict[2]
+	address, err = generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       standard.mnemonic,
+		InputMnemonicFormat: MnemonicElectrum,
+		InputLanguage:       common.Japanese,
+		InputPath:           "m/44'/0'/0'/0/0",
+	})
+	assert.NoError(t, err)
+	seed, _ = hex.DecodeString(standard.seed)
+	expected, err = generator.fromSeed(seed, "m/0/0", ScriptP2PKH, &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Address, address.Address)
+	assert.True(t, strings.HasPrefix(address.Address, "1"))
+}
+
+// Electrum lib/tests/test_wallet_vertical.py, the first receiving and change addresses
+func TestHDSegWitAddress_Generate_ElectrumWallet(t *testing.T) {
+	generator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	for mnemonic, addresses := range map[string][]string{
+		"bitter grass shiver impose acquire brush forget axis eager alone wine silver": {
+			"bc1q3g5tmkmlvxryhh843v4dz026avatc0zzr6h3af", "bc1qdy94n2q5qcp0kg7v9yzwe6wvfkhnvyzje7nx2p"},
+		"cycle rocket west magnet parrot shuffle foot correct salt library feed song": {
+			"1NNkttn1YvVGdqBW4PR6zvc3Zx3H5owKRf", "1KSezYMhAJMWqFbVFB2JshYg69UpmEXR4D"},
+	} {
+		for change, expected := range addresses {
+			address, err := generator.Generate(map[GenerateArgs]interface{}{
+				InputMnemonic:       mnemonic,
+				InputMnemonicFormat: MnemonicElectrum,
+				InputPath:           "m/84'/0'/0'/" + []string{"0", "1"}[change] + "/0",
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, address.Address)
+		}
+	}
+}
+
+func TestElectrumPath(t *testing.T) {
+	path, err := ElectrumPath(ElectrumSegwit, "m/84'/0'/0'/1/3")
+	assert.NoError(t, err)
+	assert.Equal(t, "m/0'/1/3", path)
+	path, err = ElectrumPath(ElectrumStandard, "m/0/7")
+	assert.NoError(t, err)
+	assert.Equal(t, "m/0/7", path)
+	for _, invalid := range []string{"m", "m/0", ""} {
+		_, err = ElectrumPath(ElectrumStandard, invalid)
+		assert.Equal(t, ElectrumPathInvalid, err, invalid)
+	}
+
+	_, err = NewHDSegWitAddress(GetSeedGenerator(common.GetWordList())).Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       electrumSeedDict[0].mnemonic,
+		InputMnemonicFormat: MnemonicElectrum,
+		InputPath:           "m",
+	})
+	assert.Equal(t, ElectrumPathInvalid, err)
+}
+
+// A phrase that fails the BIP39 checksum but carries the Electrum standard version is not guessed to be Electrum.
+func TestHDSegWitAddress_Generate_ElectrumNotGuessed(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	generator := NewHDSegWitAddress(testSeedGenerator)
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank abuse"
+	seedType, ok := ElectrumSeedVersion(mnemonic)
+	assert.True(t, ok)
+	assert.Equal(t, ElectrumStandard, seedType)
+
+	_, err := generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic: mnemonic,
+		InputPath:     "m/84'/0'/0'/0/0",
+	})
+	assert.Equal(t, testSeedGenerator.ValidateMnemonic(common.English, mnemonic), err)
+
+	address, err := generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       mnemonic,
+		InputMnemonicFormat: MnemonicElectrum,
+		InputPath:           "m/84'/0'/0'/0/0",
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(address.Address, "1"))
+}
@@ -0,0 +1,238 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+const (
+	// HardwareRNGDevicePath is the Linux hardware random number generator device.
+	HardwareRNGDevicePath = "/dev/hwrng"
+	// DefaultAssessedMinEntropy the min-entropy per byte claimed for a source, lower claims make the health tests
+	// less sensitive and less likely to fail on a healthy source.
+	DefaultAssessedMinEntropy = 4.0
+	// healthTestAlphaExp the false positive probability of the health tests is 2^-20, as NIST SP 800-90B recommends.
+	healthTestAlphaExp = 20
+	// aptWindowSize the adaptive proportion test window for non binary samples.
+	aptWindowSize = 512
+)
+
+var (
+	EntropySourceUnhealthy = errors.New("Entropy source failed a health test, generation is disabled")
+	EntropySourcesEmpty    = errors.New("Entropy mixer needs at least one source")
+)
+
+// EntropySource provides the random bytes of the mnemonic entropy.
+// Read fills p entirely or returns an error, a short read is an error.
+type EntropySource interface {
+	Read(p []byte) (int, error)
+}
+
+// HealthTestError reports the NIST SP 800-90B continuous health test that failed.
+type HealthTestError struct {
+	Test   string
+	Sample byte
+	Count  int
+	Cutoff int
+}
+
+func (e *HealthTestError) Error() string {
+	return fmt.Sprintf("Entropy source %s test failed, sample 0x%02x seen %d times, cutoff %d", e.Test, e.Sample, e.Count, e.Cutoff)
+}
+
+func (e *HealthTestError) Cause() error {
+	return EntropySourceUnhealthy
+}
+
+// OSEntropySource reads the operating system random number generator through crypto/rand.
+type OSEntropySource struct {
+}
+
+func (o OSEntropySource) Read(p []byte) (int, error) {
+	return io.ReadFull(rand.Reader, p)
+}
+
+// DeviceEntropySource reads a random number generator device file such as HardwareRNGDevicePath.
+// The file is opened for every read, so the device may be plugged after the source is created.
+type DeviceEntropySource struct {
+	Path string
+}
+
+func NewDeviceEntropySource(path string) DeviceEntropySource {
+	return DeviceEntropySource{Path: path}
+}
+
+func (d DeviceEntropySource) Read(p []byte) (int, error) {
+	device, err := os.Open(d.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer device.Close()
+	return io.ReadFull(device, p)
+}
+
+// DeterministicEntropySource expands a seed into SHA256(seed || counter) blocks.
+// It is reproducible and must only be used in tests.
+type DeterministicEntropySource struct {
+	mutex   sync.Mutex
+	seed    []byte
+	counter uint64
+	buffer  []byte
+}
+
+func NewDeterministicEntropySource(seed []byte) *DeterministicEntropySource {
+	return &DeterministicEntropySource{seed: append([]byte{}, seed...)}
+}
+
+func (d *DeterministicEntropySource) Read(p []byte) (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for len(d.buffer) < len(p) {
+		counter := make([]byte, 8)
+		binary.BigEndian.PutUint64(counter, d.counter)
+		block := sha256.Sum256(append(append([]byte{}, d.seed...), counter...))
+		d.buffer = append(d.buffer, block[:]...)
+		d.counter++
+	}
+	n := copy(p, d.buffer)
+	d.buffer = d.buffer[n:]
+	return n, nil
+}
+
+// MixedEntropySource combines several sources, every 32 bytes block is the SHA256 of a 32 bytes block of every source.
+// The output is unpredictable as long as one source is. Every source is health tested on its own, so a failing
+// source fails the mixer closed instead of being hidden by the others.
+type MixedEntropySource struct {
+	sources []EntropySource
+}
+
+func NewMixedEntropySource(sources ...EntropySource) (*MixedEntropySource, error) {
+	if len(sources) == 0 {
+		return nil, EntropySourcesEmpty
+	}
+	mixed := &MixedEntropySource{sources: make([]EntropySource, 0, len(sources))}
+	for _, source := range sources {
+		mixed.sources = append(mixed.sources, NewHealthTestedEntropySource(source, DefaultAssessedMinEntropy))
+	}
+	return mixed, nil
+}
+
+func (m *MixedEntropySource) Read(p []byte) (int, error) {
+	block := make([]byte, sha256.Size)
+	for offset := 0; offset < len(p); offset += sha256.Size {
+		hash := sha256.New()
+		for _, source := range m.sources {
+			if _, err := source.Read(block); err != nil {
+				return offset, err
+			}
+			hash.Write(block)
+		}
+		copy(p[offset:], hash.Sum(nil))
+	}
+	return len(p), nil
+}
+
+// HealthTestedEntropySource runs the NIST SP 800-90B continuous health tests, the repetition count test and the
+// adaptive proportion test, on every byte read from its source. The tests keep their state across reads.
+// Once a test fails the source fails closed: every later read returns an error and no bytes.
+type HealthTestedEntropySource struct {
+	mutex     sync.Mutex
+	source    EntropySource
+	rctCutoff int
+	aptCutoff int
+	failure   error
+
+	rctLast   byte
+	rctCount  int
+	aptSample byte
+	aptCount  int
+	aptSeen   int
+}
+
+// NewHealthTestedEntropySource wraps source with the health tests, minEntropy is the min-entropy per byte claimed
+// for source, from above 0 to 8 bits.
+func NewHealthTestedEntropySource(source EntropySource, minEntropy float64) *HealthTestedEntropySource {
+	if tested, ok := source.(*HealthTestedEntropySource); ok {
+		return tested
+	}
+	return &HealthTestedEntropySource{
+		source:    source,
+		rctCutoff: repetitionCountCutoff(minEntropy),
+		aptCutoff: adaptiveProportionCutoff(minEntropy),
+	}
+}
+
+func (h *HealthTestedEntropySource) Read(p []byte) (int, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.failure != nil {
+		return 0, h.failure
+	}
+	if _, err := h.source.Read(p); err != nil {
+		return 0, err
+	}
+	for _, sample := range p {
+		if err := h.test(sample); err != nil {
+			h.failure = err
+			for i := range p {
+				p[i] = 0
+			}
+			logger.Error("Entropy source health test failed", zap.Error(err))
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (h *HealthTestedEntropySource) test(sample byte) error {
+	if h.rctCount > 0 && sample == h.rctLast {
+		h.rctCount++
+		if h.rctCount >= h.rctCutoff {
+			return &HealthTestError{Test: "repetition count", Sample: sample, Count: h.rctCount, Cutoff: h.rctCutoff}
+		}
+	} else {
+		h.rctLast = sample
+		h.rctCount = 1
+	}
+
+	if h.aptSeen == 0 {
+		h.aptSample = sample
+		h.aptCount = 1
+	} else if sample == h.aptSample {
+		h.aptCount++
+		if h.aptCount >= h.aptCutoff {
+			return &HealthTestError{Test: "adaptive proportion", Sample: sample, Count: h.aptCount, Cutoff: h.aptCutoff}
+		}
+	}
+	h.aptSeen = (h.aptSeen + 1) % aptWindowSize
+	return nil
+}
+
+// repetitionCountCutoff is C = 1 + ceil(-log2(alpha) / H).
+func repetitionCountCutoff(minEntropy float64) int {
+	return 1 + int(math.Ceil(healthTestAlphaExp/minEntropy))
+}
+
+// adaptiveProportionCutoff is C = 1 + CRITBINOM(W, 2^-H, 1 - alpha), the smallest count whose binomial cumulative
+// probability reaches 1 - alpha.
+func adaptiveProportionCutoff(minEntropy float64) int {
+	p := math.Pow(2, -minEntropy)
+	target := 1 - math.Pow(2, -healthTestAlphaExp)
+	pmf := math.Pow(1-p, aptWindowSize)
+	cdf := pmf
+	k := 0
+	for cdf < target && k < aptWindowSize {
+		pmf = pmf * float64(aptWindowSize-k) / float64(k+1) * p / (1 - p)
+		k++
+		cdf += pmf
+	}
+	return 1 + k
+}
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// constantEntropySource is a stuck source that returns the same byte forever.
+type constantEntropySource byte
+
+func (c constantEntropySource) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(c)
+	}
+	return len(p), nil
+}
+
+// biasedEntropySource returns 0 every other byte and a counter otherwise, it never repeats a byte twice in a row.
+type biasedEntropySource struct {
+	counter byte
+}
+
+func (b *biasedEntropySource) Read(p []byte) (int, error) {
+	for i := range p {
+		if i%2 == 0 {
+			p[i] = 0
+		} else {
+			b.counter = b.counter%255 + 1
+			p[i] = b.counter
+		}
+	}
+	return len(p), nil
+}
+
+func TestHealthTestCutoff(t *testing.T) {
+	// NIST SP 800-90B table 2, W = 512 and alpha = 2^-20
+	for minEntropy, cutoff := range map[float64]int{1: 311, 2: 177, 4: 62, 8: 13} {
+		assert.Equal(t, cutoff, adaptiveProportionCutoff(minEntropy))
+	}
+	assert.Equal(t, 21, repetitionCountCutoff(1))
+	assert.Equal(t, 6, repetitionCountCutoff(DefaultAssessedMinEntropy))
+	assert.Equal(t, 4, repetitionCountCutoff(8))
+}
+
+func TestHealthTestedEntropySource(t *testing.T) {
+	stuck := NewHealthTestedEntropySource(constantEntropySource(0x42), DefaultAssessedMinEntropy)
+	buffer := make([]byte, 32)
+	_, err := stuck.Read(buffer)
+	var healthErr *HealthTestError
+	if assert.ErrorAs(t, err, &healthErr) {
+		assert.Equal(t, "repetition count", healthErr.Test)
+		assert.Equal(t, byte(0x42), healthErr.Sample)
+		assert.Equal(t, 6, healthErr.Count)
+	}
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+	assert.Equal(t, make([]byte, 32), buffer)
+
+	biased := NewHealthTestedEntropySource(&biasedEntropySource{}, DefaultAssessedMinEntropy)
+	_, err = biased.Read(make([]byte, 512))
+	if assert.ErrorAs(t, err, &healthErr) {
+		assert.Equal(t, "adaptive proportion", healthErr.Test)
+		assert.Equal(t, 62, healthErr.Count)
+	}
+	// fail closed, a failed source never recovers
+	_, err = biased.Read(make([]byte, 1))
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+
+	healthy := NewHealthTestedEntropySource(OSEntropySource{}, DefaultAssessedMinEntropy)
+	for i := 0; i < 64; i++ {
+		_, err = healthy.Read(buffer)
+		assert.NoError(t, err)
+	}
+}
+
+func TestSeedGeneratorFailClosed(t *testing.T) {
+	testSeedGenerator := NewSeedGenerator(common.GetWordList(), constantEntropySource(0))
+	_, err := testSeedGenerator.NewMnemonic(common.English, Word12)
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+	_, err = testSeedGenerator.NewMnemonic(common.English, Word24)
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+}
+
+func TestDeterministicEntropySource(t *testing.T) {
+	first := NewSeedGenerator(common.GetWordList(), NewDeterministicEntropySource([]byte("seed")))
+	second := NewSeedGenerator(common.GetWordList(), NewDeterministicEntropySource([]byte("seed")))
+	for _, count := range []WordCount{Word12, Word24} {
+		firstMnemonic, err := first.NewMnemonic(common.English, count)
+		assert.NoError(t, err)
+		secondMnemonic, err := second.NewMnemonic(common.English, count)
+		assert.NoError(t, err)
+		assert.Equal(t, firstMnemonic, secondMnemonic)
+		assert.NoError(t, first.ValidateMnemonic(common.English, firstMnemonic))
+	}
+
+	source := NewDeterministicEntropySource([]byte("seed"))
+	block := make([]byte, 40)
+	_, err := io.ReadFull(source, block)
+	assert.NoError(t, err)
+	expected := sha256.Sum256(append([]byte("seed"), 0, 0, 0, 0, 0, 0, 0, 0))
+	assert.Equal(t, expected[:], block[:32])
+	expected = sha256.Sum256(append([]byte("seed"), 0, 0, 0, 0, 0, 0, 0, 1))
+	assert.Equal(t, expected[:8], block[32:])
+}
+
+func TestMixedEntropySource(t *testing.T) {
+	mixed, err := NewMixedEntropySource(NewDeterministicEntropySource([]byte("a")), NewDeterministicEntropySource([]byte("b")))
+	assert.NoError(t, err)
+	output := make([]byte, 48)
+	_, err = mixed.Read(output)
+	assert.NoError(t, err)
+	a, b := make([]byte, 64), make([]byte, 64)
+	_, _ = NewDeterministicEntropySource([]byte("a")).Read(a)
+	_, _ = NewDeterministicEntropySource([]byte("b")).Read(b)
+	first := sha256.Sum256(append(append([]byte{}, a[:32]...), b[:32]...))
+	second := sha256.Sum256(append(append([]byte{}, a[32:]...), b[32:]...))
+	assert.Equal(t, first[:], output[:32])
+	assert.Equal(t, second[:16], output[32:])
+
+	// a stuck source is not hidden by a healthy one
+	mixed, err = NewMixedEntropySource(OSEntropySource{}, constantEntropySource(0xff))
+	assert.NoError(t, err)
+	_, err = mixed.Read(output)
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+
+	_, err = NewMixedEntropySource()
+	assert.Equal(t, EntropySourcesEmpty, err)
+}
+
+func TestDeviceEntropySource(t *testing.T) {
+	devicePath := filepath.Join(t.TempDir(), "hwrng")
+	content := make([]byte, 64)
+	_, _ = NewDeterministicEntropySource([]byte("device")).Read(content)
+	assert.NoError(t, os.WriteFile(devicePath, content, 0600))
+
+	buffer := make([]byte, 32)
+	_, err := NewDeviceEntropySource(devicePath).Read(buffer)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(content[:32], buffer))
+
+	_, err = NewDeviceEntropySource(devicePath).Read(make([]byte, 65))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = NewDeviceEntropySource(filepath.Join(t.TempDir(), "missing")).Read(buffer)
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+var (
+	EmptyPrefixError                = errors.New("Word prefix must be not empty")
+	PartialMnemonicWordCountInvalid = errors.New("Partial mnemonic word count must be 11, 14, 17, 20 or 23")
+)
+
+// AmbiguousWordError reports an abbreviated mnemonic word that matches more than one word of the word list.
+type AmbiguousWordError struct {
+	Position   int
+	Prefix     string
+	Candidates []string
+}
+
+func (e *AmbiguousWordError) Error() string {
+	return fmt.Sprintf("Mnemonic word #%d %q is ambiguous, candidates %v", e.Position+1, e.Prefix, e.Candidates)
+}
+
+// CompleteWord returns the words of the language word list that start with prefix, in word list order.
+// The comparison ignores accents, so "eleve" matches "élève".
+func (g *SeedGenerator) CompleteWord(input common.Language, prefix string) ([]string, error) {
+	if !common.IsSupportLanguage(input) {
+		return nil, unSupportLanguageError()
+	}
+	foldedPrefix := common.FoldWord(strings.TrimSpace(prefix))
+	if foldedPrefix == "" {
+		return nil, EmptyPrefixError
+	}
+	candidates := make([]string, 0)
+	for _, word := range g.bip39Word[input] {
+		if strings.HasPrefix(common.FoldWord(word), foldedPrefix) {
+			candidates = append(candidates, word)
+		}
+	}
+	return candidates, nil
+}
+
+// ExpandMnemonic replaces every abbreviated word of phrase with the full word of the word list.
+// BIP39 words are unique by their first 4 letters, so "leg winn than year" expands to "legal winner thank year".
+// An abbreviation that matches no word returns InvalidWordError, one that matches several words returns AmbiguousWordError.
+// The expanded phrase is not checksum validated, call ValidateMnemonic on it.
+func (g *SeedGenerator) ExpandMnemonic(input common.Language, phrase string) (string, error) {
+	if !common.IsSupportLanguage(input) {
+		return "", unSupportLanguageError()
+	}
+	words := strings.Fields(norm.NFKD.String(phrase))
+	expanded := make([]string, len(words))
+	for position, word := range words {
+		if index, ok := g.wordIndex[input][word]; ok {
+			expanded[position] = g.bip39Word[input][index]
+			continue
+		}
+		candidates, err := g.CompleteWord(input, word)
+		if err != nil {
+			return "", err
+		}
+		switch len(candidates) {
+		case 0:
+			return "", &InvalidWordError{Position: position, Word: word, Language: input}
+		case 1:
+			expanded[position] = candidates[0]
+		default:
+			exact := ""
+			for _, candidate := range candidates {
+				if common.FoldWord(candidate) == common.FoldWord(word) {
+					exact = candidate
+				}
+			}
+			if exact == "" {
+				return "", &AmbiguousWordError{Position: position, Prefix: word, Candidates: candidates}
+			}
+			expanded[position] = exact
+		}
+	}
+	return strings.Join(expanded, common.MnemonicSeparator(input)), nil
+}
+
+// FinalWords returns every word that completes the partial phrase (11, 14, 17, 20 or 23 words) to a checksum valid
+// mnemonic, in word list order. The last word carries the remaining entropy bits and the checksum bits, so there are
+// 128 final words for 12 words mnemonics down to 8 for 24 words mnemonics.
+func (g *SeedGenerator) FinalWords(input common.Language, phrase string) ([]string, error) {
+	if !common.IsSupportLanguage(input) {
+		return nil, unSupportLanguageError()
+	}
+	words := strings.Fields(norm.NFKD.String(phrase))
+	seedLen, ok := mnemonicLen[WordCount(len(words)+1)]
+	if !ok {
+		return nil, PartialMnemonicWordCountInvalid
+	}
+	indexes := make([]int, len(words)+1)
+	for position, word := range words {
+		index, ok := g.wordIndex[input][word]
+		if !ok {
+			return nil, &InvalidWordError{Position: position, Word: word, Language: input}
+		}
+		indexes[position] = index
+	}
+	checkSumLen := uint(seedLen / 32)
+	freeBitsLen := 11 - checkSumLen
+	finalWords := make([]string, 0, 1<<freeBitsLen)
+	for free := 0; free < 1<<freeBitsLen; free++ {
+		indexes[len(words)] = free << checkSumLen
+		entropyBytes := intsToBytes(indexes)[:seedLen/8]
+		finalIndex := free<<checkSumLen | int(checkSumBinary(entropyBytes, seedLen))
+		finalWords = append(finalWords, g.bip39Word[input][finalIndex])
+	}
+	return finalWords, nil
+}
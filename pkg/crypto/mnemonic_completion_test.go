@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"testing"
+)
+
+func TestCompleteWord(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	words, err := testSeedGenerator.CompleteWord(common.English, "aban")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abandon"}, words)
+
+	words, err = testSeedGenerator.CompleteWord(common.English, "ab")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abandon", "ability", "able", "about", "above", "absent", "absorb", "abstract", "absurd", "abuse"}, words)
+
+	words, err = testSeedGenerator.CompleteWord(common.French, "elev")
+	assert.NoError(t, err)
+	if assert.Len(t, words, 1) {
+		assert.Equal(t, "élève", norm.NFC.String(words[0]))
+	}
+
+	_, err = testSeedGenerator.CompleteWord(common.English, " ")
+	assert.Equal(t, EmptyPrefixError, err)
+}
+
+func TestExpandMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	mnemonic, err := testSeedGenerator.ExpandMnemonic(common.English, "lega winn than year wave saus wort usef legal winn than yell")
+	assert.NoError(t, err)
+	assert.Equal(t, "legal winner thank year wave sausage worth useful legal winner thank yellow", mnemonic)
+	assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, mnemonic))
+
+	_, err = testSeedGenerator.ExpandMnemonic(common.English, "lega wi than year")
+	var ambiguousErr *AmbiguousWordError
+	if assert.ErrorAs(t, err, &ambiguousErr) {
+		assert.Equal(t, 1, ambiguousErr.Position)
+		assert.Contains(t, ambiguousErr.Candidates, "winner")
+		assert.Contains(t, ambiguousErr.Candidates, "window")
+	}
+
+	_, err = testSeedGenerator.ExpandMnemonic(common.English, "lega xyz")
+	var wordErr *InvalidWordError
+	assert.ErrorAs(t, err, &wordErr)
+}
+
+func TestFinalWords(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	expected := map[WordCount]int{Word12: 128, Word15: 64, Word18: 32, Word21: 16, Word24: 8}
+	for count, finalLen := range expected {
+		mnemonic, err := testSeedGenerator.NewMnemonic(common.English, count)
+		assert.NoError(t, err)
+		words := strings.Fields(mnemonic)
+		partial := strings.Join(words[:len(words)-1], " ")
+		finalWords, err := testSeedGenerator.FinalWords(common.English, partial)
+		assert.NoError(t, err)
+		assert.Len(t, finalWords, finalLen)
+		assert.Contains(t, finalWords, words[len(words)-1])
+		for _, finalWord := range finalWords {
+			assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, partial+" "+finalWord))
+		}
+	}
+	finalWords, err := testSeedGenerator.FinalWords(common.English, strings.Repeat("abandon ", 11))
+	assert.NoError(t, err)
+	assert.Equal(t, "about", finalWords[0])
+
+	_, err = testSeedGenerator.FinalWords(common.English, strings.Repeat("abandon ", 12))
+	assert.Equal(t, PartialMnemonicWordCountInvalid, err)
+}
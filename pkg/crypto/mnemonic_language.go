@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"golang.org/x/text/unicode/norm"
+	"sort"
+	"strings"
+)
+
+var (
+	MnemonicLanguageUnknown = errors.New("Mnemonic is not a valid phrase of any loaded word list")
+)
+
+// AmbiguousLanguageError reports a phrase that is a valid mnemonic in several word lists,
+// the caller has to name the language.
+type AmbiguousLanguageError struct {
+	Languages []common.Language
+}
+
+func (e *AmbiguousLanguageError) Error() string {
+	return fmt.Sprintf("Mnemonic is valid in several word lists %v, the language must be given", e.Languages)
+}
+
+// DetectLanguage returns the language of phrase among the loaded word lists.
+// A candidate list must contain every word and give a matching checksum, so that lists sharing words
+// such as chinese_simplified and chinese_traditional or english and french are told apart.
+func (g *SeedGenerator) DetectLanguage(phrase string) (common.Language, error) {
+	words := strings.Fields(norm.NFKD.String(phrase))
+	languages := make([]common.Language, 0, len(g.wordIndex))
+	for language := range g.wordIndex {
+		if common.IsSupportLanguage(language) {
+			languages = append(languages, language)
+		}
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i] < languages[j] })
+
+	candidates := make([]common.Language, 0, 1)
+	for _, language := range languages {
+		if !g.containsWords(language, words) {
+			continue
+		}
+		if g.ValidateMnemonic(language, phrase) == nil {
+			candidates = append(candidates, language)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", MnemonicLanguageUnknown
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", &AmbiguousLanguageError{Languages: candidates}
+	}
+}
+
+// TranslateMnemonic decodes phrase in the from language to its entropy and encodes the entropy in the to language,
+// an empty from is detected with DetectLanguage. It returns the translated mnemonic and the detected from language.
+// The entropy is kept but the BIP39 seed is not: PBKDF2 stretches the words themselves, so the translated
+// mnemonic derives other keys and addresses and does not restore the wallet of phrase.
+func (g *SeedGenerator) TranslateMnemonic(from common.Language, to common.Language, phrase string) (string, common.Language, error) {
+	if from == "" {
+		detected, err := g.DetectLanguage(phrase)
+		if err != nil {
+			return "", "", err
+		}
+		from = detected
+	}
+	entropy, err := g.MnemonicToEntropy(from, phrase)
+	if err != nil {
+		return "", from, err
+	}
+	translated, err := g.EntropyToMnemonic(entropy, to)
+	if err != nil {
+		return "", from, err
+	}
+	return translated, from, nil
+}
+
+func (g *SeedGenerator) containsWords(language common.Language, words []string) bool {
+	for _, word := range words {
+		if _, ok := g.wordIndex[language][word]; !ok {
+			return false
+		}
+	}
+	return true
+}
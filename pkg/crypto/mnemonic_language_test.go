@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	language, err := testSeedGenerator.DetectLanguage("legal winner thank year wave sausage worth useful legal winner thank yellow")
+	assert.NoError(t, err)
+	assert.Equal(t, common.English, language)
+	language, err = testSeedGenerator.DetectLanguage("mexicano crosta farpa empolgar chatice fartura olaria sogro centeio defesa dedal multar")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Portuguese, language)
+
+	for _, expected := range common.SupportLanguageSlice() {
+		mnemonic, err := testSeedGenerator.NewMnemonic(expected, Word12)
+		assert.NoError(t, err)
+		language, err = testSeedGenerator.DetectLanguage(mnemonic)
+		// the chinese lists share characters, a random phrase may rarely have a valid checksum in both
+		var ambiguousErr *AmbiguousLanguageError
+		if errors.As(err, &ambiguousErr) {
+			assert.Contains(t, ambiguousErr.Languages, expected)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, expected, language)
+	}
+
+	_, err = testSeedGenerator.DetectLanguage("legal winner thank year wave sausage worth useful legal winner thank thank")
+	assert.Equal(t, MnemonicLanguageUnknown, err)
+	_, err = testSeedGenerator.DetectLanguage("legal winner thank year wave sausage worth useful legal winner thank bitcoin")
+	assert.Equal(t, MnemonicLanguageUnknown, err)
+}
+
+func TestTranslateMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	english := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	japanese, from, err := testSeedGenerator.TranslateMnemonic("", common.Japanese, english)
+	assert.NoError(t, err)
+	assert.Equal(t, common.English, from)
+	japaneseWords := common.GetWordList()[common.Japanese]
+	assert.Equal(t, strings.Repeat(japaneseWords[0]+"　", 11)+japaneseWords[3], japanese)
+
+	translated, from, err := testSeedGenerator.TranslateMnemonic("", common.English, japanese)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Japanese, from)
+	assert.Equal(t, english, translated)
+
+	// the entropy is kept but the seed is not
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	chinese, _, err := testSeedGenerator.TranslateMnemonic(common.English, common.ChineseSimplified, mnemonic)
+	assert.NoError(t, err)
+	englishEntropy, _ := testSeedGenerator.MnemonicToEntropy(common.English, mnemonic)
+	chineseEntropy, _ := testSeedGenerator.MnemonicToEntropy(common.ChineseSimplified, chinese)
+	assert.Equal(t, englishEntropy, chineseEntropy)
+	assert.False(t, bytes.Equal(testSeedGenerator.NewSeed(mnemonic, ""), testSeedGenerator.NewSeed(chinese, "")))
+
+	_, _, err = testSeedGenerator.TranslateMnemonic(common.French, common.English, mnemonic)
+	var invalidWordErr *InvalidWordError
+	assert.ErrorAs(t, err, &invalidWordErr)
+	_, _, err = testSeedGenerator.TranslateMnemonic(common.English, "klingon", mnemonic)
+	assert.Error(t, err)
+}
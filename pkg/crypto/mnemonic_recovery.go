@@ -0,0 +1,355 @@
+package crypto
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"go.uber.org/zap"
+	"golang.org/x/text/unicode/norm"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// UnknownWordMark marks a mnemonic position whose word is unknown.
+	UnknownWordMark = "?"
+	// MaxRecoveryUnknownWords at most 2 positions may be tried against the whole word list, 2048^2 candidates.
+	MaxRecoveryUnknownWords = 2
+	// MaxRecoveryCombinations bounds the search space, the product of the candidates of every position summed over
+	// the missing word arrangements. Typos do not count as unknown words but multiply it by their near words.
+	MaxRecoveryCombinations uint64 = 1 << 32
+	// MaxRecoveryCandidates bounds the checksum valid mnemonics returned, a TargetAddress keeps them below it.
+	MaxRecoveryCandidates     = 10000
+	defaultRecoveryDistance   = 2
+	recoveryChunkSize         = 1 << 12
+	recoveryDefaultTargetPath = "m/84'/0'/0'/0/0"
+)
+
+var (
+	RecoveryUnknownWordsExceeded = errors.Errorf("Mnemonic recovery supports at most %d unknown or missing words", MaxRecoveryUnknownWords)
+	RecoveryCombinationsExceeded = errors.Errorf("Mnemonic recovery search space exceeds %d combinations, fix some typos or lower the max distance", MaxRecoveryCombinations)
+	RecoveryCandidatesExceeded   = errors.Errorf("Mnemonic recovery found more than %d candidates, set a target address", MaxRecoveryCandidates)
+)
+
+// RecoveryRequest describes a mnemonic that fails validation.
+// Phrase may contain typos, UnknownWordMark for unknown words and miss up to MaxRecoveryUnknownWords words.
+// A typo is replaced by the words within MaxDistance edits (2 by default), a word without such neighbours is unknown.
+// When TargetAddress is set, only the candidates whose HDSegWitAddress of Path (m/84'/0'/0'/0/0 by default)
+// with Password on Network (DefaultNetwork by default) is TargetAddress are returned.
+type RecoveryRequest struct {
+	Language      common.Language
+	Phrase        string
+	MaxDistance   int
+	Workers       int
+	Password      string
+	Path          string
+	TargetAddress string
+	Network       Network
+}
+
+// recoveryLayout is one arrangement of the phrase, the word indexes that may appear at every position.
+type recoveryLayout struct {
+	options [][]int
+	total   uint64
+}
+
+type recoveryJob struct {
+	layout *recoveryLayout
+	start  uint64
+	end    uint64
+}
+
+// RecoverMnemonic returns the checksum valid mnemonics that can be built from the request phrase, sorted.
+// A search space above MaxRecoveryCombinations or more than MaxRecoveryCandidates results is an error.
+// The search runs on request.Workers goroutines (runtime.NumCPU() by default) and stops when ctx is done.
+func (g *SeedGenerator) RecoverMnemonic(ctx context.Context, request RecoveryRequest) ([]string, error) {
+	if !common.IsSupportLanguage(request.Language) {
+		return nil, unSupportLanguageError()
+	}
+	layouts, err := g.recoveryLayouts(request)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := g.recoveryMatcher(request)
+	if err != nil {
+		return nil, err
+	}
+	workers := request.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan recoveryJob, workers)
+	var (
+		mutex    sync.Mutex
+		found    = make(map[string]bool)
+		firstErr error
+		wait     sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			indexes := make([]int, 0, int(Word24))
+			for job := range jobs {
+				for combination := job.start; combination < job.end; combination++ {
+					indexes = job.layout.indexes(combination, indexes[:0])
+					if !indexesChecksumValid(indexes) {
+						continue
+					}
+					mnemonic := g.indexesToMnemonic(request.Language, indexes)
+					match, matchErr := matcher(mnemonic)
+					mutex.Lock()
+					if matchErr != nil && firstErr == nil {
+						firstErr = matchErr
+						cancel()
+					} else if match && !found[mnemonic] {
+						if len(found) >= MaxRecoveryCandidates {
+							if firstErr == nil {
+								firstErr = RecoveryCandidatesExceeded
+								cancel()
+							}
+						} else {
+							found[mnemonic] = true
+						}
+					}
+					mutex.Unlock()
+				}
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}()
+	}
+produce:
+	for i := range layouts {
+		for start := uint64(0); start < layouts[i].total; start += recoveryChunkSize {
+			end := start + recoveryChunkSize
+			if end > layouts[i].total {
+				end = layouts[i].total
+			}
+			select {
+			case jobs <- recoveryJob{layout: &layouts[i], start: start, end: end}:
+			case <-ctx.Done():
+				break produce
+			}
+		}
+	}
+	close(jobs)
+	wait.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, len(found))
+	for mnemonic := range found {
+		candidates = append(candidates, mnemonic)
+	}
+	sort.Strings(candidates)
+	logger.Info("RecoverMnemonic finished", zap.Any("candidates", len(candidates)), zap.Any("layouts", len(layouts)))
+	return candidates, nil
+}
+
+func (g *SeedGenerator) recoveryLayouts(request RecoveryRequest) ([]recoveryLayout, error) {
+	maxDistance := request.MaxDistance
+	if maxDistance <= 0 {
+		maxDistance = defaultRecoveryDistance
+	}
+	words := strings.Fields(norm.NFKD.String(request.Phrase))
+	missing := -1
+	for count := range mnemonicLen {
+		if gap := int(count) - len(words); gap >= 0 && gap <= MaxRecoveryUnknownWords && (missing < 0 || gap < missing) {
+			missing = gap
+		}
+	}
+	if missing < 0 {
+		return nil, MnemonicWordCountInvalid
+	}
+	allIndexes := make([]int, len(g.bip39Word[request.Language]))
+	for i := range allIndexes {
+		allIndexes[i] = i
+	}
+	unknown := missing
+	options := make([][]int, len(words))
+	for position, word := range words {
+		if index, ok := g.wordIndex[request.Language][word]; ok {
+			options[position] = []int{index}
+			continue
+		}
+		if word != UnknownWordMark {
+			options[position] = g.nearWords(request.Language, word, maxDistance)
+		}
+		if len(options[position]) == 0 {
+			options[position] = allIndexes
+			unknown++
+		}
+	}
+	if unknown > MaxRecoveryUnknownWords {
+		return nil, RecoveryUnknownWordsExceeded
+	}
+	layouts := make([]recoveryLayout, 0)
+	var total uint64
+	for _, gaps := range gapPositions(len(words)+missing, missing) {
+		layout := recoveryLayout{options: make([][]int, 0, len(words)+missing), total: 1}
+		next := 0
+		for position := 0; position < len(words)+missing; position++ {
+			if len(gaps) > 0 && gaps[0] == position {
+				layout.options = append(layout.options, allIndexes)
+				gaps = gaps[1:]
+			} else {
+				layout.options = append(layout.options, options[next])
+				next++
+			}
+			radix := uint64(len(layout.options[position]))
+			if layout.total > MaxRecoveryCombinations/radix {
+				return nil, RecoveryCombinationsExceeded
+			}
+			layout.total *= radix
+		}
+		if total += layout.total; total > MaxRecoveryCombinations {
+			return nil, RecoveryCombinationsExceeded
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts, nil
+}
+
+func (g *SeedGenerator) recoveryMatcher(request RecoveryRequest) (func(string) (bool, error), error) {
+	if request.TargetAddress == "" {
+		return func(string) (bool, error) { return true, nil }, nil
+	}
+	path := request.Path
+	if path == "" {
+		path = recoveryDefaultTargetPath
+	}
+	if !common.IsInvalidPath(path) {
+		return nil, errors.Errorf("Mnemonic recovery invalid path %s", path)
+	}
+	network := request.Network
+	if network == "" {
+		network = defaultNetwork
+	}
+	params, err := network.Params()
+	if err != nil {
+		return nil, err
+	}
+	scriptType := ScriptTypeOfPath(path)
+	addressGenerator := NewHDSegWitAddress(g)
+	return func(mnemonic string) (bool, error) {
+		address, err := addressGenerator.fromSeed(g.NewSeed(mnemonic, request.Password), path, scriptType, params)
+		if err != nil {
+			return false, err
+		}
+		return address.Address == request.TargetAddress, nil
+	}, nil
+}
+
+// nearWords returns the index of the words within maxDistance edits of word, accents are ignored.
+func (g *SeedGenerator) nearWords(input common.Language, word string, maxDistance int) []int {
+	folded := []rune(common.FoldWord(word))
+	near := make([]int, 0)
+	for index, candidate := range g.bip39Word[input] {
+		if editDistance(folded, []rune(common.FoldWord(candidate)), maxDistance) <= maxDistance {
+			near = append(near, index)
+		}
+	}
+	return near
+}
+
+func (g *SeedGenerator) indexesToMnemonic(input common.Language, indexes []int) string {
+	words := make([]string, len(indexes))
+	for i, index := range indexes {
+		words[i] = g.bip39Word[input][index]
+	}
+	return strings.Join(words, common.MnemonicSeparator(input))
+}
+
+// indexes decodes combination as a mixed radix number, one digit for every position.
+func (l *recoveryLayout) indexes(combination uint64, indexes []int) []int {
+	for position := len(l.options) - 1; position >= 0; position-- {
+		radix := uint64(len(l.options[position]))
+		indexes = append(indexes, l.options[position][combination%radix])
+		combination /= radix
+	}
+	for i, j := 0, len(indexes)-1; i < j; i, j = i+1, j-1 {
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	}
+	return indexes
+}
+
+// indexesChecksumValid packs the 11 bits word indexes and compares the trailing checksum bits with the SHA256 of the entropy.
+func indexesChecksumValid(indexes []int) bool {
+	seedLen := mnemonicLen[WordCount(len(indexes))]
+	checkSum := checkSumBinary(intsToBytes(indexes)[:seedLen/8], seedLen)
+	return indexes[len(indexes)-1]&(1<<uint(seedLen/32)-1) == int(checkSum)
+}
+
+// gapPositions returns every sorted combination of count positions out of size.
+func gapPositions(size int, count int) [][]int {
+	if count == 0 {
+		return [][]int{{}}
+	}
+	combinations := make([][]int, 0)
+	for first := 0; first <= size-count; first++ {
+		for _, rest := range gapPositions(size-first-1, count-1) {
+			combination := []int{first}
+			for _, position := range rest {
+				combination = append(combination, position+first+1)
+			}
+			combinations = append(combinations, combination)
+		}
+	}
+	return combinations
+}
+
+// editDistance is the Levenshtein distance of a and b, it stops early once every path exceeds limit.
+func editDistance(a []rune, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
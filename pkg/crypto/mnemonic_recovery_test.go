@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"context"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const recoveryMnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+func TestRecoverMnemonic_Typo(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	candidates, err := testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner thank year wave sausage worth useful legal winer thank yelow",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, candidates, recoveryMnemonic)
+	for _, candidate := range candidates {
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, candidate))
+	}
+}
+
+func TestRecoverMnemonic_UnknownAndMissing(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	candidates, err := testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner thank year wave sausage worth useful legal winner thank ?",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 128)
+	assert.Contains(t, candidates, recoveryMnemonic)
+
+	candidates, err = testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner thank year sausage worth useful legal winner thank yellow",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, candidates, recoveryMnemonic)
+
+	_, err = testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner ? year ? sausage worth useful legal winner thank ?",
+	})
+	assert.Equal(t, RecoveryUnknownWordsExceeded, err)
+}
+
+func TestRecoverMnemonic_TargetAddress(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	address, err := NewHDSegWitAddress(testSeedGenerator).Generate(map[GenerateArgs]interface{}{
+		InputMnemonic: recoveryMnemonic,
+		InputPath:     recoveryDefaultTargetPath,
+	})
+	assert.NoError(t, err)
+	candidates, err := testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language:      common.English,
+		Phrase:        "legal winner thank year wave sausage worth useful legal winner thank ?",
+		TargetAddress: address.Address,
+		Workers:       4,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{recoveryMnemonic}, candidates)
+}
+
+func TestRecoverMnemonic_Cancel(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := testSeedGenerator.RecoverMnemonic(ctx, RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner ? year wave sausage worth useful legal winner thank ?",
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRecoverMnemonic_Bounds(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	_, err := testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legl winer thnk yer wav sausge wrth usefl legl winer thnk yelow",
+	})
+	assert.Equal(t, RecoveryCombinationsExceeded, err)
+
+	// 2048^2 combinations, a sixteenth of them checksum valid
+	_, err = testSeedGenerator.RecoverMnemonic(context.Background(), RecoveryRequest{
+		Language: common.English,
+		Phrase:   "legal winner ? year wave sausage worth useful legal winner thank ?",
+	})
+	assert.Equal(t, RecoveryCandidatesExceeded, err)
+}
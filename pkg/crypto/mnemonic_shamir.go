@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// A mnemonic share is the payload threshold (1 byte) || share index (1 byte) || share value (the entropy length),
+// followed by the leading bits of the SHA256 of the payload, at least 8 bits and as many as needed to fill the last
+// 11 bits word. A 12 words mnemonic gives 14 words shares, a 24 words mnemonic gives 26 words shares.
+
+const (
+	mnemonicShareHeaderLen      = 2
+	mnemonicShareMinChecksumLen = 8
+)
+
+var (
+	MnemonicShareWordCountInvalid = errors.New("Mnemonic share word count must be 14, 17, 20, 23 or 26")
+	MnemonicShareChecksumInvalid  = errors.New("Mnemonic share checksum mismatch, the share is corrupted")
+	MnemonicShareHeaderInvalid    = errors.New("Mnemonic share threshold or index is invalid")
+	MnemonicSharesMismatch        = errors.New("Mnemonic shares must have the same threshold and length")
+	MnemonicNotEnoughShares       = errors.New("Mnemonic shares are less than the threshold")
+)
+
+// MnemonicShare is a decoded share of a k-of-n split mnemonic.
+type MnemonicShare struct {
+	Threshold int
+	Index     int
+	Value     []byte
+}
+
+// SplitMnemonic splits the entropy of mnemonic into shareCount shares with Shamir secret sharing over GF(256),
+// any threshold of them recover the mnemonic. Every share is a mnemonic of the same language word list.
+func (g *SeedGenerator) SplitMnemonic(input common.Language, mnemonic string, threshold int, shareCount int) ([]string, error) {
+	entropy, err := g.MnemonicToEntropy(input, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := shamirSplit(threshold, shareCount, entropy, g.entropySource)
+	if err != nil {
+		return nil, err
+	}
+	mnemonics := make([]string, len(shares))
+	for i, share := range shares {
+		mnemonicShare := MnemonicShare{Threshold: threshold, Index: int(share.index), Value: share.value}
+		mnemonics[i] = g.encodeMnemonicShare(input, mnemonicShare)
+	}
+	return mnemonics, nil
+}
+
+// CombineMnemonicShares recovers the mnemonic from at least threshold shares of SplitMnemonic.
+// Every share is checksum validated before the recombination.
+func (g *SeedGenerator) CombineMnemonicShares(input common.Language, mnemonics []string) (string, error) {
+	if len(mnemonics) == 0 {
+		return "", MnemonicNotEnoughShares
+	}
+	shares := make([]shamirShare, 0, len(mnemonics))
+	threshold := 0
+	for i, mnemonic := range mnemonics {
+		share, err := g.DecodeMnemonicShare(input, mnemonic)
+		if err != nil {
+			return "", errors.Wrapf(err, "share #%d", i+1)
+		}
+		if i == 0 {
+			threshold = share.Threshold
+		} else if share.Threshold != threshold || len(share.Value) != len(shares[0].value) {
+			return "", MnemonicSharesMismatch
+		}
+		shares = append(shares, shamirShare{index: byte(share.Index), value: share.Value})
+	}
+	if len(shares) < threshold {
+		return "", MnemonicNotEnoughShares
+	}
+	entropy, err := shamirInterpolate(shares[:threshold], 0)
+	if err != nil {
+		return "", err
+	}
+	return g.EntropyToMnemonic(entropy, input)
+}
+
+// DecodeMnemonicShare reads the threshold, the index and the value of a share and verifies its checksum.
+func (g *SeedGenerator) DecodeMnemonicShare(input common.Language, mnemonic string) (*MnemonicShare, error) {
+	if !common.IsSupportLanguage(input) {
+		return nil, unSupportLanguageError()
+	}
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	payloadLen := 0
+	for _, seedLen := range mnemonicLen {
+		if mnemonicShareWordCount(int(seedLen)/8) == len(words) {
+			payloadLen = int(seedLen)/8 + mnemonicShareHeaderLen
+		}
+	}
+	if payloadLen == 0 {
+		return nil, MnemonicShareWordCountInvalid
+	}
+	indexes, err := g.wordIndexes(input, words)
+	if err != nil {
+		return nil, err
+	}
+	payload := intsToBytes(indexes)[:payloadLen]
+	if !mnemonicShareChecksumValid(payload, indexes) {
+		return nil, MnemonicShareChecksumInvalid
+	}
+	share := &MnemonicShare{
+		Threshold: int(payload[0]),
+		Index:     int(payload[1]),
+		Value:     append([]byte{}, payload[mnemonicShareHeaderLen:]...),
+	}
+	if share.Threshold < 1 || share.Index < 1 {
+		return nil, MnemonicShareHeaderInvalid
+	}
+	return share, nil
+}
+
+func (g *SeedGenerator) encodeMnemonicShare(input common.Language, share MnemonicShare) string {
+	payload := append([]byte{byte(share.Threshold), byte(share.Index)}, share.Value...)
+	words := make([]string, 0, mnemonicShareWordCount(len(share.Value)))
+	for _, index := range mnemonicShareIndexes(payload, cap(words)) {
+		words = append(words, g.bip39Word[input][index])
+	}
+	return strings.Join(words, common.MnemonicSeparator(input))
+}
+
+// mnemonicShareIndexes splits the payload followed by the leading bits of its SHA256 into count word indexes.
+func mnemonicShareIndexes(payload []byte, count int) []int {
+	hash := sha256.Sum256(payload)
+	return bytesToInts(append(append([]byte{}, payload...), hash[:]...), count)
+}
+
+// mnemonicShareChecksumValid compares the word indexes with the ones of the payload and its checksum bits.
+func mnemonicShareChecksumValid(payload []byte, indexes []int) bool {
+	expected := mnemonicShareIndexes(payload, len(indexes))
+	for i, index := range indexes {
+		if index != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mnemonicShareWordCount(valueLen int) int {
+	return ((valueLen+mnemonicShareHeaderLen)*8 + mnemonicShareMinChecksumLen + 10) / 11
+}
+
+func (s MnemonicShare) String() string {
+	return fmt.Sprintf("MnemonicShare{Threshold: %d, Index: %d}", s.Threshold, s.Index)
+}
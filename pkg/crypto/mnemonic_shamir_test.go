@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSplitMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for mnemonic, wordCount := range map[string]int{
+		"legal winner thank year wave sausage worth useful legal winner thank yellow":                                                                              14,
+		"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold": 26,
+	} {
+		shares, err := testSeedGenerator.SplitMnemonic(common.English, mnemonic, 3, 5)
+		assert.NoError(t, err)
+		assert.Len(t, shares, 5)
+		for _, share := range shares {
+			assert.Len(t, strings.Fields(share), wordCount)
+		}
+		for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+			selected := make([]string, 0, len(subset))
+			for _, i := range subset {
+				selected = append(selected, shares[i])
+			}
+			combined, err := testSeedGenerator.CombineMnemonicShares(common.English, selected)
+			assert.NoError(t, err)
+			assert.Equal(t, mnemonic, combined)
+		}
+		_, err = testSeedGenerator.CombineMnemonicShares(common.English, shares[:2])
+		assert.Equal(t, MnemonicNotEnoughShares, err)
+	}
+
+	japanese := "あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あいこくしん あおぞら"
+	shares, err := testSeedGenerator.SplitMnemonic(common.Japanese, japanese, 2, 2)
+	assert.NoError(t, err)
+	combined, err := testSeedGenerator.CombineMnemonicShares(common.Japanese, shares)
+	assert.NoError(t, err)
+	assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.Japanese, combined))
+
+	_, err = testSeedGenerator.SplitMnemonic(common.English, "legal winner thank year wave sausage worth useful legal winner thank yellow", 3, 2)
+	assert.Equal(t, ShamirThresholdInvalid, err)
+	_, err = testSeedGenerator.SplitMnemonic(common.English, "legal winner thank year wave sausage worth useful legal winner thank yellow", 2, 256)
+	assert.Equal(t, ShamirThresholdInvalid, err)
+}
+
+func TestDecodeMnemonicShare(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	shares, err := testSeedGenerator.SplitMnemonic(common.English, mnemonic, 2, 3)
+	assert.NoError(t, err)
+	for i, share := range shares {
+		decoded, err := testSeedGenerator.DecodeMnemonicShare(common.English, share)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, decoded.Threshold)
+		assert.Equal(t, i+1, decoded.Index)
+		assert.Len(t, decoded.Value, 16)
+	}
+
+	entropy, err := testSeedGenerator.MnemonicToEntropy(common.English, mnemonic)
+	assert.NoError(t, err)
+	share := testSeedGenerator.encodeMnemonicShare(common.English, MnemonicShare{Threshold: 2, Index: 1, Value: entropy})
+	decoded, err := testSeedGenerator.DecodeMnemonicShare(common.English, share)
+	assert.NoError(t, err)
+	assert.Equal(t, entropy, decoded.Value)
+	words := strings.Fields(share)
+	words[5] = "abandon"
+	corrupted := strings.Join(words, " ")
+	_, err = testSeedGenerator.DecodeMnemonicShare(common.English, corrupted)
+	assert.Equal(t, MnemonicShareChecksumInvalid, err)
+	_, err = testSeedGenerator.CombineMnemonicShares(common.English, []string{shares[1], corrupted})
+	assert.Equal(t, MnemonicShareChecksumInvalid, errors.Cause(err))
+
+	_, err = testSeedGenerator.DecodeMnemonicShare(common.English, mnemonic)
+	assert.Equal(t, MnemonicShareWordCountInvalid, err)
+
+	other, err := testSeedGenerator.SplitMnemonic(common.English,
+		"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold", 2, 3)
+	assert.NoError(t, err)
+	_, err = testSeedGenerator.CombineMnemonicShares(common.English, []string{shares[0], other[1]})
+	assert.Equal(t, MnemonicSharesMismatch, err)
+}
+
+func TestShamirSplitThresholdOne(t *testing.T) {
+	secret := []byte{0x01, 0x02, 0x03}
+	shares, err := shamirSplit(1, 3, secret, OSEntropySource{})
+	assert.NoError(t, err)
+	for i, share := range shares {
+		assert.Equal(t, byte(i+1), share.index)
+		assert.Equal(t, secret, share.value)
+	}
+}
@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip32"
+	"strings"
+)
+
+// Network is the bitcoin network of the generated addresses and extended keys.
+type Network string
+
+const (
+	MainNet  Network = "mainnet"
+	TestNet3 Network = "testnet3"
+	SigNet   Network = "signet"
+	RegTest  Network = "regtest"
+)
+
+var (
+	NetworkInvalid = errors.New("Network must be mainnet, testnet3, signet or regtest")
+
+	// SigNetParams the default signet, https://github.com/bitcoin/bips/blob/master/bip-0325.mediawiki
+	// btcd v0.22 has no signet parameters. Only the network magic and the address and key encodings are set,
+	// they are the testnet3 ones except the network magic.
+	SigNetParams = chaincfg.Params{
+		Name:                    string(SigNet),
+		Net:                     wire.BitcoinNet(0x40cf030a),
+		DefaultPort:             "38333",
+		Bech32HRPSegwit:         "tb",
+		PubKeyHashAddrID:        0x6f,
+		ScriptHashAddrID:        0xc4,
+		PrivateKeyID:            0xef,
+		WitnessPubKeyHashAddrID: 0x03,
+		WitnessScriptHashAddrID: 0x28,
+		HDPrivateKeyID:          [4]byte{0x04, 0x35, 0x83, 0x94}, // tprv
+		HDPublicKeyID:           [4]byte{0x04, 0x35, 0x87, 0xcf}, // tpub
+		HDCoinType:              1,
+	}
+
+	networkParams = map[Network]*chaincfg.Params{
+		MainNet:  &chaincfg.MainNetParams,
+		TestNet3: &chaincfg.TestNet3Params,
+		SigNet:   &SigNetParams,
+		RegTest:  &chaincfg.RegressionNetParams,
+	}
+	defaultNetwork = MainNet
+)
+
+// ParseNetwork returns the network of name, case insensitive.
+func ParseNetwork(name string) (Network, error) {
+	network := Network(strings.ToLower(name))
+	if _, ok := networkParams[network]; !ok {
+		return "", NetworkInvalid
+	}
+	return network, nil
+}
+
+// Params returns the chain parameters of the network: bech32 HRP, P2PKH and P2SH versions and BIP32 version bytes.
+func (n Network) Params() (*chaincfg.Params, error) {
+	params, ok := networkParams[n]
+	if !ok {
+		return nil, NetworkInvalid
+	}
+	return params, nil
+}
+
+// SetDefaultNetwork sets the network of the generators when a request has no InputNetwork, mainnet by default.
+func SetDefaultNetwork(network Network) error {
+	if _, ok := networkParams[network]; !ok {
+		return NetworkInvalid
+	}
+	defaultNetwork = network
+	return nil
+}
+
+func DefaultNetwork() Network {
+	return defaultNetwork
+}
+
+// networkParamsOf returns the parameters of the InputNetwork of args, of the default network when args has none.
+func networkParamsOf(args map[GenerateArgs]interface{}) (*chaincfg.Params, error) {
+	if network, ok := args[InputNetwork]; ok {
+		return network.(Network).Params()
+	}
+	return defaultNetwork.Params()
+}
+
+// serializeKey serializes key with the BIP32 version bytes of params, go-bip32 only knows the mainnet xprv and xpub.
+func serializeKey(key *bip32.Key, params *chaincfg.Params) string {
+	versioned := *key
+	if key.IsPrivate {
+		versioned.Version = params.HDPrivateKeyID[:]
+	} else {
+		versioned.Version = params.HDPublicKeyID[:]
+	}
+	return versioned.B58Serialize()
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip32"
+	"strings"
+	"testing"
+)
+
+const networkTestMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestParseNetwork(t *testing.T) {
+	for _, name := range []string{"mainnet", "testnet3", "signet", "RegTest"} {
+		network, err := ParseNetwork(name)
+		assert.NoError(t, err)
+		params, err := network.Params()
+		assert.NoError(t, err)
+		assert.Equal(t, strings.ToLower(name), params.Name)
+	}
+	_, err := ParseNetwork("testnet4")
+	assert.Equal(t, NetworkInvalid, err)
+	assert.Equal(t, NetworkInvalid, SetDefaultNetwork("testnet4"))
+	assert.Equal(t, MainNet, DefaultNetwork())
+}
+
+func TestHDSegWitAddress_Generate_Network(t *testing.T) {
+	generator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	generate := func(network Network) *Address {
+		address, err := generator.Generate(map[GenerateArgs]interface{}{
+			InputPath:     "m/84'/1'/0'/0/0",
+			InputMnemonic: networkTestMnemonic,
+			InputNetwork:  network,
+		})
+		assert.NoError(t, err)
+		return address
+	}
+	// BIP84 testnet vector
+	testnet := generate(TestNet3)
+	assert.Equal(t, "tb1q6rz28mcfaxtmd6v789l9rrlrusdprr9pqcpvkl", testnet.Address)
+	assert.Equal(t, "testnet3", testnet.Network)
+	assert.True(t, strings.HasPrefix(testnet.PrivateKey, "tprv"))
+	assert.True(t, strings.HasPrefix(testnet.PublicKey, "tpub"))
+
+	signet := generate(SigNet)
+	assert.Equal(t, testnet.Address, signet.Address)
+	assert.Equal(t, testnet.PrivateKey, signet.PrivateKey)
+
+	regtest := generate(RegTest)
+	assert.True(t, strings.HasPrefix(regtest.Address, "bcrt1q"))
+	decoded, err := btcutil.DecodeAddress(regtest.Address, &chaincfg.RegressionNetParams)
+	assert.NoError(t, err)
+	testnetDecoded, err := btcutil.DecodeAddress(testnet.Address, &chaincfg.TestNet3Params)
+	assert.NoError(t, err)
+	assert.Equal(t, testnetDecoded.ScriptAddress(), decoded.ScriptAddress())
+
+	// the same keys as mainnet, only the version bytes differ
+	mainnet := generate(MainNet)
+	assert.True(t, strings.HasPrefix(mainnet.Address, "bc1q"))
+	mainnetKey, err := bip32.B58Deserialize(mainnet.PrivateKey)
+	assert.NoError(t, err)
+	testnetKey, err := bip32.B58Deserialize(testnet.PrivateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, mainnetKey.Key, testnetKey.Key)
+	assert.Equal(t, mainnetKey.ChainCode, testnetKey.ChainCode)
+
+	_, err = generator.Generate(map[GenerateArgs]interface{}{
+		InputPath:     "m/84'/1'/0'/0/0",
+		InputMnemonic: networkTestMnemonic,
+		InputNetwork:  Network("testnet4"),
+	})
+	assert.Equal(t, NetworkInvalid, err)
+}
+
+func TestMultiSigAddress_Generate_Network(t *testing.T) {
+	args := map[GenerateArgs]interface{}{
+		MultiSigNum: MultiSigNumPair{M: 3, N: 2},
+		MultiSigPublicKey: [][]byte{
+			[]byte("020f8796e0f870a9a3b269be3b1e78e380c9b569885f0de98a9ff061c4a66e79d2"),
+			[]byte("02dfa8990f3f015ff20e9b31b85ea36d47470220615fb2ac1597e20fc830727b25"),
+			[]byte("03fbfbdc5df9c60e4b747805552686199e85299a5e87804dbb66a14597ddabcf29")},
+	}
+	mainnet, err := MultiSigAddress{}.Generate(args)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mainnet.Address, "3"))
+
+	assert.NoError(t, SetDefaultNetwork(RegTest))
+	defer SetDefaultNetwork(MainNet)
+	regtest, err := MultiSigAddress{}.Generate(args)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(regtest.Address, "2"))
+	assert.Equal(t, "regtest", regtest.Network)
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"strings"
+)
+
+// ScriptType is the output script of a single key address.
+type ScriptType string
+
+const (
+	ScriptP2PKH      ScriptType = "p2pkh"
+	ScriptP2SHP2WPKH ScriptType = "p2sh-p2wpkh"
+	ScriptP2WPKH     ScriptType = "p2wpkh"
+	ScriptP2TR       ScriptType = "p2tr"
+	// ScriptP2SHMultiSig the n-of-m multisig of MultiSigAddress, it has no single key to override with.
+	ScriptP2SHMultiSig ScriptType = "p2sh-multisig"
+)
+
+var (
+	ScriptTypeInvalid = errors.New("Script type must be p2pkh, p2sh-p2wpkh, p2wpkh or p2tr")
+
+	// purposeScriptType the script type of the BIP44, BIP49, BIP84 and BIP86 purposes
+	purposeScriptType = map[uint32]ScriptType{
+		44: ScriptP2PKH,
+		49: ScriptP2SHP2WPKH,
+		84: ScriptP2WPKH,
+		86: ScriptP2TR,
+	}
+)
+
+// ParseScriptType returns the script type of name, case insensitive.
+func ParseScriptType(name string) (ScriptType, error) {
+	scriptType := ScriptType(strings.ToLower(name))
+	switch scriptType {
+	case ScriptP2PKH, ScriptP2SHP2WPKH, ScriptP2WPKH, ScriptP2TR:
+		return scriptType, nil
+	}
+	return "", ScriptTypeInvalid
+}
+
+// ScriptTypeOfPath returns the script type of the hardened purpose of path: P2PKH for 44', P2SH-P2WPKH for 49',
+// P2WPKH for 84' and P2TR for 86'. Any other path keeps the native segwit P2WPKH.
+func ScriptTypeOfPath(path string) ScriptType {
+	children := strings.Split(path, "/")
+	if len(children) > 1 && strings.HasSuffix(children[1], "'") {
+		if scriptType, ok := purposeScriptType[common.GetChild(children[1])-0x80000000]; ok {
+			return scriptType
+		}
+	}
+	return ScriptP2WPKH
+}
+
+// scriptAddress encodes the compressed public key as an address of scriptType on the network of params.
+func scriptAddress(publicKey []byte, scriptType ScriptType, params *chaincfg.Params) (btcutil.Address, error) {
+	keyHash := btcutil.Hash160(publicKey)
+	switch scriptType {
+	case ScriptP2PKH:
+		return btcutil.NewAddressPubKeyHash(keyHash, params)
+	case ScriptP2SHP2WPKH:
+		// the redeem script is the P2WPKH witness program, OP_0 <20 bytes key hash>
+		redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(keyHash).Script()
+		if err != nil {
+			return nil, err
+		}
+		return btcutil.NewAddressScriptHash(redeemScript, params)
+	case ScriptP2WPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(keyHash, params)
+	case ScriptP2TR:
+		return newTaprootAddress(publicKey, params)
+	}
+	return nil, ScriptTypeInvalid
+}
@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScriptTypeOfPath(t *testing.T) {
+	assert.Equal(t, ScriptP2PKH, ScriptTypeOfPath("m/44'/0'/0'/0/0"))
+	assert.Equal(t, ScriptP2SHP2WPKH, ScriptTypeOfPath("m/49'/1'/0'/0/0"))
+	assert.Equal(t, ScriptP2WPKH, ScriptTypeOfPath("m/84'/0'/0'/1/7"))
+	assert.Equal(t, ScriptP2WPKH, ScriptTypeOfPath("m/0'/0/0"))
+	assert.Equal(t, ScriptP2WPKH, ScriptTypeOfPath("m/44/0/0"))
+
+	scriptType, err := ParseScriptType("P2SH-P2WPKH")
+	assert.NoError(t, err)
+	assert.Equal(t, ScriptP2SHP2WPKH, scriptType)
+	_, err = ParseScriptType(string(ScriptP2SHMultiSig))
+	assert.Equal(t, ScriptTypeInvalid, err)
+}
+
+// BIP44, BIP49 and BIP84 test vectors of "abandon ... about"
+func TestHDSegWitAddress_Generate_ScriptType(t *testing.T) {
+	generator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	for _, vector := range []struct {
+		path       string
+		network    Network
+		scriptType ScriptType
+		address    string
+	}{
+		{"m/44'/0'/0'/0/0", MainNet, ScriptP2PKH, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{"m/49'/0'/0'/0/0", MainNet, ScriptP2SHP2WPKH, "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{"m/49'/1'/0'/0/0", TestNet3, ScriptP2SHP2WPKH, "2Mww8dCYPUpKHofjgcXcBCEGmniw9CoaiD2"},
+		{"m/84'/0'/0'/0/0", MainNet, ScriptP2WPKH, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{"m/84'/0'/0'/1/0", MainNet, ScriptP2WPKH, "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"},
+	} {
+		address, err := generator.Generate(map[GenerateArgs]interface{}{
+			InputMnemonic: networkTestMnemonic,
+			InputPath:     vector.path,
+			InputNetwork:  vector.network,
+		})
+		assert.NoError(t, err, vector.path)
+		assert.Equal(t, vector.address, address.Address, vector.path)
+		assert.Equal(t, string(vector.scriptType), address.ScriptType, vector.path)
+	}
+
+	// the override wins over the purpose
+	address, err := generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:   networkTestMnemonic,
+		InputPath:       "m/44'/0'/0'/0/0",
+		InputScriptType: ScriptP2WPKH,
+	})
+	assert.NoError(t, err)
+	legacy, _ := btcutil.DecodeAddress("1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", &chaincfg.MainNetParams)
+	witness, _ := btcutil.NewAddressWitnessPubKeyHash(legacy.ScriptAddress(), &chaincfg.MainNetParams)
+	assert.Equal(t, witness.EncodeAddress(), address.Address)
+	assert.Equal(t, string(ScriptP2WPKH), address.ScriptType)
+
+	_, err = generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:   networkTestMnemonic,
+		InputPath:       "m/44'/0'/0'/0/0",
+		InputScriptType: ScriptP2SHMultiSig,
+	})
+	assert.Equal(t, ScriptTypeInvalid, err)
+}
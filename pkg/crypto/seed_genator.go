@@ -1,8 +1,6 @@
 package crypto
 
 import (
-	"bytes"
-	"crypto/rand"
 	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
@@ -10,7 +8,7 @@ import (
 	common "github.com/pzhenzhou/crypto-prototype/pkg"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/pbkdf2"
-	"strconv"
+	"golang.org/x/text/unicode/norm"
 	"strings"
 	"sync"
 )
@@ -20,87 +18,191 @@ type WordCount int
 
 const (
 	Bit128Len SeedLen = 128
+	Bit160Len SeedLen = 160
+	Bit192Len SeedLen = 192
+	Bit224Len SeedLen = 224
 	Bit256Len SeedLen = 256
 
 	Word12 WordCount = 12
+	Word15 WordCount = 15
+	Word18 WordCount = 18
+	Word21 WordCount = 21
 	Word24 WordCount = 24
 
 	passwordSalt string = "mnemonic"
 )
 
 var (
-	supportLanguage        = common.SupportLanguageSlice()
-	unSupportLanguageError = errors.Errorf("current only support language %v", supportLanguage)
-	unSupportWordLenError  = errors.New("current only 12 or 24  mnemonic phrase")
-	once                   sync.Once
-	seedGeneratorInstance  *SeedGenerator
-	SeedSplitError         = errors.New("Seed Split Error. binary % 11 != 0")
-	mnemonicLen            = map[WordCount]SeedLen{
+	unSupportWordLenError = errors.New("current only 12, 15, 18, 21 or 24 mnemonic phrase")
+	once                  sync.Once
+	seedGeneratorInstance *SeedGenerator
+	defaultEntropySource  EntropySource = OSEntropySource{}
+	SeedSplitError                      = errors.New("Seed Split Error. binary % 11 != 0")
+	mnemonicLen                         = map[WordCount]SeedLen{
 		Word12: Bit128Len,
+		Word15: Bit160Len,
+		Word18: Bit192Len,
+		Word21: Bit224Len,
 		Word24: Bit256Len,
 	}
 	logger = common.GetLogger()
+
+	MnemonicWordCountInvalid = errors.New("Mnemonic word count must be 12, 15, 18, 21 or 24")
+	EntropyLenInvalid        = errors.New("Entropy length must be 128, 160, 192, 224 or 256 bits")
 )
 
+// InvalidWordError reports a mnemonic word that is not part of the BIP39 word list of the language.
+type InvalidWordError struct {
+	Position int
+	Word     string
+	Language common.Language
+}
+
+func (e *InvalidWordError) Error() string {
+	return fmt.Sprintf("Mnemonic word #%d %q is not in the %s word list", e.Position+1, e.Word, e.Language)
+}
+
+// ChecksumMismatchError reports a mnemonic whose checksum bits do not match the SHA256 of its entropy.
+type ChecksumMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("Mnemonic checksum mismatch. expected %s, actual %s", e.Expected, e.Actual)
+}
+
+func unSupportLanguageError() error {
+	return errors.Errorf("current only support language %v", common.SupportLanguageSlice())
+}
+
 type SeedGenerator struct {
-	bip39Word map[common.Language][]string
+	bip39Word     map[common.Language][]string
+	wordIndex     map[common.Language]map[string]int
+	entropySource EntropySource
+}
+
+// SetDefaultEntropySource sets the source of the GetSeedGenerator instance, OSEntropySource by default.
+// It must be called before the first GetSeedGenerator.
+func SetDefaultEntropySource(source EntropySource) {
+	defaultEntropySource = source
 }
 
 func GetSeedGenerator(words map[common.Language][]string) *SeedGenerator {
 	if seedGeneratorInstance == nil {
 		once.Do(func() {
 			logger.Info("SeedGenerator Init", zap.Any("WordsLen", len(words)))
-			seedGeneratorInstance = &SeedGenerator{
-				bip39Word: words,
-			}
+			seedGeneratorInstance = NewSeedGenerator(words, defaultEntropySource)
 		})
 	}
 	return seedGeneratorInstance
 }
 
+// NewSeedGenerator returns a SeedGenerator that reads its entropy from source.
+// Every read runs the continuous health tests of HealthTestedEntropySource, generation fails once a test fails.
+func NewSeedGenerator(words map[common.Language][]string, source EntropySource) *SeedGenerator {
+	return &SeedGenerator{
+		bip39Word:     words,
+		wordIndex:     indexWords(words),
+		entropySource: NewHealthTestedEntropySource(source, DefaultAssessedMinEntropy),
+	}
+}
+
 // NewMnemonic
 // 1. Generate a 128-bit random number and add 4 bits of checksum to the random number to get a 132-bit number
 // 2. in every 11 bits to do the cut, get 12 binary numbers
 // 3. Use the number generated in the 2nd step to look up the word list defined by BIP39, so as to get 12  mnemonics
+// The same steps apply to 160, 192, 224 and 256 bits entropy, which give 15, 18, 21 and 24 words.
 // https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
 func (g *SeedGenerator) NewMnemonic(input common.Language, count WordCount) (string, error) {
 	if !common.IsSupportLanguage(input) {
-		return "", unSupportLanguageError
+		return "", unSupportLanguageError()
 	}
 	if _, ok := mnemonicLen[count]; !ok {
 		return "", unSupportWordLenError
 	}
-	if entropy, err := entropy(mnemonicLen[count]); err == nil {
+	if entropy, err := entropy(g.entropySource, mnemonicLen[count]); err == nil {
 		if mnemonicArray, newMnemonicErr := mnemonic(entropy, g.bip39Word[input]); newMnemonicErr != nil {
 			return "", newMnemonicErr
 		} else {
-			return strings.Join(mnemonicArray, " "), nil
+			return strings.Join(mnemonicArray, common.MnemonicSeparator(input)), nil
 		}
 	} else {
 		return "", err
 	}
 }
 
+// ValidateMnemonic checks that the phrase has a supported word count, that every word belongs to the
+// word list of the given language and that the trailing checksum bits match the SHA256 of the entropy.
+func (g *SeedGenerator) ValidateMnemonic(input common.Language, phrase string) error {
+	_, err := g.MnemonicToEntropy(input, phrase)
+	return err
+}
+
+// MnemonicToEntropy is the inverse of NewMnemonic. It NFKD normalizes the phrase, maps every word back to its 11 bits index,
+// splits the bits into entropy and checksum and returns the entropy once the checksum is verified.
+func (g *SeedGenerator) MnemonicToEntropy(input common.Language, phrase string) ([]byte, error) {
+	if !common.IsSupportLanguage(input) {
+		return nil, unSupportLanguageError()
+	}
+	words := strings.Fields(norm.NFKD.String(phrase))
+	if _, ok := mnemonicLen[WordCount(len(words))]; !ok {
+		return nil, MnemonicWordCountInvalid
+	}
+	indexes, err := g.wordIndexes(input, words)
+	if err != nil {
+		return nil, err
+	}
+	seedLen := mnemonicLen[WordCount(len(words))]
+	checkSumLen := int(seedLen / 32)
+	encoded := intsToBytes(indexes)
+	entropyBytes := encoded[:seedLen/8]
+	checkSum := checkSumBinary(entropyBytes, seedLen)
+	if actual := encoded[seedLen/8] >> uint(8-checkSumLen); actual != checkSum {
+		return nil, &ChecksumMismatchError{
+			Expected: fmt.Sprintf("%0*b", checkSumLen, checkSum),
+			Actual:   fmt.Sprintf("%0*b", checkSumLen, actual),
+		}
+	}
+	return entropyBytes, nil
+}
+
+// EntropyToMnemonic encodes caller supplied entropy as a mnemonic, the same way NewMnemonic encodes
+// the entropy read from crypto/rand.
+func (g *SeedGenerator) EntropyToMnemonic(entropy []byte, input common.Language) (string, error) {
+	if !common.IsSupportLanguage(input) {
+		return "", unSupportLanguageError()
+	}
+	seedLen := SeedLen(len(entropy) * 8)
+	if !isSupportSeedLen(seedLen) {
+		return "", EntropyLenInvalid
+	}
+	intSlice := bytesToInts(bytesEncode(entropy, seedLen), mnemonicWordCount(seedLen))
+	mnemonicArray, err := mnemonic(intSlice, g.bip39Word[input])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(mnemonicArray, common.MnemonicSeparator(input)), nil
+}
+
+// NewSeed stretches the mnemonic into a 64 bytes seed with PBKDF2-HMAC-SHA512.
+// Both the mnemonic and the "mnemonic"+password salt are NFKD normalized first as BIP39 requires,
+// so a phrase typed with precomposed or decomposed accents gives the same seed.
 func (g *SeedGenerator) NewSeed(mnemonic string, password string) []byte {
-	return pbkdf2.Key([]byte(mnemonic), []byte(passwordSalt+password), 2048, 64, sha512.New)
+	return pbkdf2.Key([]byte(norm.NFKD.String(mnemonic)), []byte(norm.NFKD.String(passwordSalt+password)), 2048, 64, sha512.New)
 }
 
-func entropy(seedLen SeedLen) ([]int, error) {
-	binaryString, randErr := randEntropy(seedLen)
+func entropy(source EntropySource, seedLen SeedLen) ([]int, error) {
+	entropyBytes, randErr := randEntropy(source, seedLen)
 	if randErr != nil {
 		logger.Error("randEntropy() error", zap.Any("seedLen", seedLen), zap.Error(randErr))
 		return nil, randErr
 	}
-	intSlice, err := bytesToInts(binaryString)
-	if err != nil {
-		logger.Error("Entropy() bytesToInts Error", zap.Any("seedLen", seedLen), zap.Error(err))
-		return nil, err
-	}
-	return intSlice, nil
+	return bytesToInts(bytesEncode(entropyBytes, seedLen), mnemonicWordCount(seedLen)), nil
 }
 
 func mnemonic(randSlices []int, words []string) ([]string, error) {
-	mnemonicSlice := make([]string, 0)
+	mnemonicSlice := make([]string, 0, len(randSlices))
 	for _, index := range randSlices {
 		word := words[index]
 		mnemonicSlice = append(mnemonicSlice, word)
@@ -108,69 +210,101 @@ func mnemonic(randSlices []int, words []string) ([]string, error) {
 	return mnemonicSlice, nil
 }
 
-func bytesToInts(byteString string) ([]int, error) {
-	byteStringLen := len(byteString)
-	if byteStringLen%11 != 0 {
-		return nil, SeedSplitError
-	}
-	var randSlice = make([]int, 0)
-	len := byteStringLen / 11
-	for i := 0; i < len; i++ {
-		start := 11 * i
-		end := (11 * i) + 11
-		strVal := byteString[start:end]
-		intVal, err := strconv.ParseInt(strVal, 2, 32)
-		if err != nil {
-			logger.Error("string convert int32 error", zap.Any("stringValue", strVal), zap.Error(err))
-			return nil, err
+func isSupportSeedLen(seedLen SeedLen) bool {
+	for _, supportLen := range mnemonicLen {
+		if supportLen == seedLen {
+			return true
 		}
-		randSlice = append(randSlice, int(intVal))
 	}
-	return randSlice, nil
+	return false
 }
 
-func checkSumBinary(binarySlices []byte, seedLen SeedLen) (string, error) {
-	sha256Bytes := sha256.Sum256(binarySlices)
-	len := int(seedLen) / 32
-	var buff bytes.Buffer
-	for _, byteValue := range sha256Bytes {
-		binary := fmt.Sprintf("%08b", byteValue)
-		if len <= 8 {
-			buffValue := binary[:len]
-			buff.WriteString(buffValue)
-			break
-		} else {
-			buff.WriteString(binary)
-			len -= 8
+func indexWords(words map[common.Language][]string) map[common.Language]map[string]int {
+	index := make(map[common.Language]map[string]int, len(words))
+	for language, wordSlice := range words {
+		index[language] = make(map[string]int, len(wordSlice))
+		for i, word := range wordSlice {
+			index[language][norm.NFKD.String(word)] = i
 		}
 	}
-	return buff.String(), nil
+	return index
 }
 
-func bytesEncode(byteSlice []byte) string {
-	var strBuffer bytes.Buffer
-	for _, byteElement := range byteSlice {
-		binaryStr := fmt.Sprintf("%08b", byteElement)
-		strBuffer.WriteString(binaryStr)
+// wordIndexes returns the word list index of every word of input.
+func (g *SeedGenerator) wordIndexes(input common.Language, words []string) ([]int, error) {
+	indexes := make([]int, len(words))
+	for position, word := range words {
+		index, ok := g.wordIndex[input][word]
+		if !ok {
+			return nil, &InvalidWordError{Position: position, Word: word, Language: input}
+		}
+		indexes[position] = index
 	}
-	return strBuffer.String()
+	return indexes, nil
 }
 
-func randEntropy(seedLen SeedLen) (string, error) {
-	entropyByteLen := int(seedLen / 8)
-	byteSlice := make([]byte, entropyByteLen)
-	_, err := rand.Read(byteSlice)
-	if err != nil {
-		logger.Error("generate crypto.rand.Read() error cause by", zap.Error(err))
-		return "", err
+// mnemonicWordCount is the number of words of seedLen entropy bits and its seedLen/32 checksum bits.
+func mnemonicWordCount(seedLen SeedLen) int {
+	return int(seedLen+seedLen/32) / 11
+}
+
+// bytesToInts splits data into count 11 bits word indexes, most significant bit first.
+// At most 18 bits are buffered, the bits above them are shifted out and masked away.
+func bytesToInts(data []byte, count int) []int {
+	indexes := make([]int, 0, count)
+	var buffer uint32
+	var bits uint
+	for _, value := range data {
+		buffer = buffer<<8 | uint32(value)
+		bits += 8
+		if bits >= 11 && len(indexes) < count {
+			bits -= 11
+			indexes = append(indexes, int(buffer>>bits&0x7ff))
+		}
 	}
+	return indexes
+}
 
-	encodeValue := bytesEncode(byteSlice)
-	checkSumValue, err := checkSumBinary(byteSlice, seedLen)
-	if err != nil {
-		logger.Error("checkSumBinary error cause by", zap.Error(err))
-		return "", err
+// intsToBytes is the inverse of bytesToInts, it packs 11 bits word indexes most significant bit first
+// and pads the last byte with zero bits.
+func intsToBytes(indexes []int) []byte {
+	data := make([]byte, 0, (len(indexes)*11+7)/8)
+	var buffer uint32
+	var bits uint
+	for _, index := range indexes {
+		buffer = buffer<<11 | uint32(index&0x7ff)
+		bits += 11
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(buffer>>bits))
+		}
+	}
+	if bits > 0 {
+		data = append(data, byte(buffer<<(8-bits)))
+	}
+	return data
+}
+
+// checkSumBinary returns the seedLen/32 (4 to 8) checksum bits of entropy, the first bits of its SHA256, right aligned.
+func checkSumBinary(entropy []byte, seedLen SeedLen) byte {
+	hash := sha256.Sum256(entropy)
+	return hash[0] >> uint(8-seedLen/32)
+}
+
+// bytesEncode returns entropy followed by a byte holding its checksum bits left aligned,
+// the bits that bytesToInts splits into the word indexes.
+func bytesEncode(entropy []byte, seedLen SeedLen) []byte {
+	encoded := make([]byte, len(entropy)+1)
+	copy(encoded, entropy)
+	encoded[len(entropy)] = checkSumBinary(entropy, seedLen) << uint(8-seedLen/32)
+	return encoded
+}
+
+func randEntropy(source EntropySource, seedLen SeedLen) ([]byte, error) {
+	byteSlice := make([]byte, seedLen/8)
+	if _, err := source.Read(byteSlice); err != nil {
+		logger.Error("generate entropy source Read() error cause by", zap.Error(err))
+		return nil, err
 	}
-	bitsRs := encodeValue + checkSumValue
-	return bitsRs, nil
+	return byteSlice, nil
 }
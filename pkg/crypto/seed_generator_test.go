@@ -5,9 +5,42 @@ import (
 	"encoding/json"
 	common "github.com/pzhenzhou/crypto-prototype/pkg"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/unicode/norm"
+	"strings"
 	"testing"
 )
 
+// https://github.com/bip32JP/bip32JP.github.io/blob/master/test_JP_BIP39.json
+// language, mnemonic (NFC as typed on a keyboard), passphrase, seed
+var nonASCIIMnemonicDict = [][]string{
+	{
+		string(common.Japanese),
+		"あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら",
+		"㍍ガバヴァぱばぐゞちぢ十人十色",
+		"a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55",
+	},
+	// The accented Latin vectors are typed in composed (NFC) form, the seeds were computed with Python hashlib
+	// pbkdf2_hmac over the NFKD forms, independently of this package.
+	{
+		string(common.French),
+		"implorer visage sonnette voyage véloce pourpre volaille tribunal implorer visage sonnette voyelle",
+		"TREZOR",
+		"ab9180b7dfdde74e5cf8781e5692e2c0b55afa8bc1987fa8e14e3fb83c88b195c53e9f939f8febc33d2958f5fcd8add57843cb318d8886130ef9c9879c826357",
+	},
+	{
+		string(common.Spanish),
+		"lino admitir bolero abrir álbum dejar acelga aprender lino admitir bolero abrir álbum dejar acelga aprender lino admitir bolero abrir álbum dejar acelga aumento",
+		"contraseña",
+		"43a38bc469d4d317b96cd9df874f5eaeda46eaea466aeba633b5f878d4a2188b6689eedb67a4fd13526848f45ca31033e3e615a8fbf7b4dbd4b482441c76e021",
+	},
+	{
+		string(common.Czech),
+		"pokoj jogurt malovat kroupa holub malvice rachot uznat hnout kasa karamel potupa",
+		"heslíčko",
+		"ecf373d6c82f14cb14fdc9f7a6b6f05f9a2f272dff5c3c4ed314e4ccf01d99835cf713ff342a200a3e1d3d6ea5b1ba4913c9b29987acae82b0fd1f6ab21ba658",
+	},
+}
+
 // https://github.com/trustwallet/wallet-core/blob/master/tests/Keystore/Data/legacy-mnemonic.json
 var mnemonicDict = `[
         [
@@ -123,3 +156,198 @@ func TestNewSeed(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	var dict = make([][]string, 0)
+	if err := json.Unmarshal([]byte(mnemonicDict), &dict); err != nil {
+		t.Fatal("Json Unmarshal Err", err)
+	}
+	for _, eleSlice := range dict {
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, eleSlice[0]), eleSlice[0])
+	}
+}
+
+func TestValidateMnemonic_Invalid(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+
+	err := testSeedGenerator.ValidateMnemonic(common.English, "abandon abandon abandon")
+	assert.Equal(t, MnemonicWordCountInvalid, err)
+
+	err = testSeedGenerator.ValidateMnemonic(common.English,
+		"legal winner thank year wave sausage worth useful legal winner thank yelow")
+	var wordErr *InvalidWordError
+	if assert.ErrorAs(t, err, &wordErr) {
+		assert.Equal(t, 11, wordErr.Position)
+		assert.Equal(t, "yelow", wordErr.Word)
+	}
+
+	err = testSeedGenerator.ValidateMnemonic(common.English,
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+	var checksumErr *ChecksumMismatchError
+	if assert.ErrorAs(t, err, &checksumErr) {
+		assert.Equal(t, "0011", checksumErr.Expected)
+		assert.Equal(t, "0000", checksumErr.Actual)
+	}
+}
+
+// https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+var entropyDict = [][]string{
+	{"00000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f", "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+	{"80808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+	{"ffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"},
+	{"9e885d952ad362caeb4efe34a8e91bd2", "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic"},
+	{"c0ba5a8e914111210f2bd131f3d5e08d", "scheme spot photo card baby mountain device kick cradle pact join borrow"},
+	{"23db8160a31d3e0dca3688ed941adbf3", "cat swing flag economy stadium alone churn speed unique patch report train"},
+	{"f30f8c1da665478f49b001d94c5fc452", "vessel ladder alter error federal sibling chat ability sun glass valve picture"},
+	{"000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent"},
+	{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f", "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal will"},
+	{"808080808080808080808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always"},
+	{"ffffffffffffffffffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when"},
+	{"0000000000000000000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+	{"68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c", "hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length"},
+	{"066dca1a2bb7e8a1db2832148ce9933eea0f3ac9548d793112d9a95c9407efad", "all hour make first leader extend hole alien behind guard gospel lava path output census museum junior mass reopen famous sing advance salt reform"},
+	{"f585c11aec520db57dd353c69554b21a89b20fb0650966fa0a9d6f74fd989d8f", "void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold"},
+}
+
+func TestEntropyToMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for _, eleSlice := range entropyDict {
+		entropy, _ := hex.DecodeString(eleSlice[0])
+		mnemonic, err := testSeedGenerator.EntropyToMnemonic(entropy, common.English)
+		assert.NoError(t, err)
+		assert.Equal(t, eleSlice[1], mnemonic)
+	}
+	_, err := testSeedGenerator.EntropyToMnemonic([]byte{0x01, 0x02}, common.English)
+	assert.Equal(t, EntropyLenInvalid, err)
+}
+
+func TestMnemonicToEntropy(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for _, eleSlice := range entropyDict {
+		entropy, err := testSeedGenerator.MnemonicToEntropy(common.English, eleSlice[1])
+		assert.NoError(t, err)
+		assert.Equal(t, eleSlice[0], hex.EncodeToString(entropy))
+	}
+	mnemonic, err := testSeedGenerator.NewMnemonic(common.English, Word24)
+	assert.NoError(t, err)
+	entropy, err := testSeedGenerator.MnemonicToEntropy(common.English, mnemonic)
+	assert.NoError(t, err)
+	roundTrip, err := testSeedGenerator.EntropyToMnemonic(entropy, common.English)
+	assert.NoError(t, err)
+	assert.Equal(t, mnemonic, roundTrip)
+}
+
+func TestNewMnemonic_AllWordCount(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for count := range mnemonicLen {
+		mnemonic, err := testSeedGenerator.NewMnemonic(common.English, count)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), int(count))
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, mnemonic))
+	}
+	_, err := testSeedGenerator.NewMnemonic(common.English, WordCount(13))
+	assert.Error(t, err)
+}
+
+func TestNewMnemonic_AllLanguage(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	entropy, _ := hex.DecodeString("00000000000000000000000000000000")
+	for _, language := range common.SupportLanguageSlice() {
+		mnemonic, err := testSeedGenerator.NewMnemonic(language, Word15)
+		assert.NoError(t, err, language)
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(language, mnemonic), language)
+
+		mnemonic, err = testSeedGenerator.EntropyToMnemonic(entropy, language)
+		assert.NoError(t, err, language)
+		roundTrip, err := testSeedGenerator.MnemonicToEntropy(language, mnemonic)
+		assert.NoError(t, err, language)
+		assert.Equal(t, entropy, roundTrip, language)
+	}
+	mnemonic, err := testSeedGenerator.EntropyToMnemonic(entropy, common.Japanese)
+	assert.NoError(t, err)
+	japaneseWords := common.GetWordList()[common.Japanese]
+	assert.Equal(t, strings.Repeat(japaneseWords[0]+"\u3000", 11)+japaneseWords[3], mnemonic)
+	// only Japanese is joined with the ideographic space
+	mnemonic, err = testSeedGenerator.EntropyToMnemonic(entropy, common.Portuguese)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("abacate ", 11)+"abater", mnemonic)
+}
+
+func TestNewSeed_NonASCII(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for _, eleSlice := range nonASCIIMnemonicDict {
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.Language(eleSlice[0]), eleSlice[1]))
+		seed := testSeedGenerator.NewSeed(eleSlice[1], eleSlice[2])
+		assert.Equal(t, eleSlice[3], hex.EncodeToString(seed))
+	}
+}
+
+// The Portuguese seed was computed with Python hashlib from the official portuguese.txt, independently of this package.
+func TestNewSeed_Portuguese(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	entropy, _ := hex.DecodeString("9e885d952ad362caeb4efe34a8e91bd2")
+	mnemonic, err := testSeedGenerator.EntropyToMnemonic(entropy, common.Portuguese)
+	assert.NoError(t, err)
+	assert.Equal(t, "mexicano crosta farpa empolgar chatice fartura olaria sogro centeio defesa dedal multar", mnemonic)
+	assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.Portuguese, mnemonic))
+	assert.Equal(t, "29d0337a11ea7f688c5e35c7b03e007067912f232d63e3a51b25887b9b69788b3f0375ad306146f0144e0464643752340d3849493cec1271ffe263be070afc39",
+		hex.EncodeToString(testSeedGenerator.NewSeed(mnemonic, "senha")))
+}
+
+func TestNewSeed_NormalizeForm(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	entropy, _ := hex.DecodeString("7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f")
+	for _, language := range []common.Language{common.French, common.Spanish, common.Czech} {
+		mnemonic, err := testSeedGenerator.EntropyToMnemonic(entropy, language)
+		assert.NoError(t, err)
+		composed := norm.NFC.String(mnemonic)
+		assert.NoError(t, testSeedGenerator.ValidateMnemonic(language, composed))
+		assert.Equal(t, testSeedGenerator.NewSeed(mnemonic, "pässwörd"),
+			testSeedGenerator.NewSeed(composed, norm.NFD.String("pässwörd")))
+	}
+}
+
+func BenchmarkNewMnemonic(b *testing.B) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := testSeedGenerator.NewMnemonic(common.English, Word24); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEntropyToMnemonic(b *testing.B) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	entropy, _ := hex.DecodeString("68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := testSeedGenerator.EntropyToMnemonic(entropy, common.English); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMnemonicToEntropy(b *testing.B) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	mnemonic := "hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := testSeedGenerator.MnemonicToEntropy(common.English, mnemonic); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFinalWords(b *testing.B) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	partial := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := testSeedGenerator.FinalWords(common.English, partial); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
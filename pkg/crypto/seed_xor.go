@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+)
+
+// Seed XOR as implemented by Coldcard: the entropy of a mnemonic is the XOR of the entropy of its parts,
+// and every part is a checksum valid BIP39 mnemonic of the same word count.
+
+const (
+	MinSeedXorParts = 2
+	MaxSeedXorParts = 4
+)
+
+var (
+	SeedXorWordCountInvalid = errors.New("Seed XOR only supports 12 or 24 words mnemonics")
+	SeedXorPartsInvalid     = errors.Errorf("Seed XOR parts must be between %d and %d", MinSeedXorParts, MaxSeedXorParts)
+	SeedXorPartsMismatch    = errors.New("Seed XOR parts must have the same word count")
+)
+
+// SplitSeedXor splits a 12 or 24 words mnemonic into parts mnemonics. The first parts-1 parts are read from the
+// generator entropy source, the last one is the XOR of the mnemonic entropy and the random parts entropy.
+func (g *SeedGenerator) SplitSeedXor(input common.Language, mnemonic string, parts int) ([]string, error) {
+	if parts < MinSeedXorParts || parts > MaxSeedXorParts {
+		return nil, SeedXorPartsInvalid
+	}
+	entropy, err := g.MnemonicToEntropy(input, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	if !isSeedXorEntropyLen(len(entropy)) {
+		return nil, SeedXorWordCountInvalid
+	}
+	last := append([]byte{}, entropy...)
+	mnemonics := make([]string, 0, parts)
+	for i := 0; i < parts-1; i++ {
+		part := make([]byte, len(entropy))
+		if _, err := g.entropySource.Read(part); err != nil {
+			return nil, err
+		}
+		xorBytes(last, part)
+		partMnemonic, err := g.EntropyToMnemonic(part, input)
+		if err != nil {
+			return nil, err
+		}
+		mnemonics = append(mnemonics, partMnemonic)
+	}
+	lastMnemonic, err := g.EntropyToMnemonic(last, input)
+	if err != nil {
+		return nil, err
+	}
+	return append(mnemonics, lastMnemonic), nil
+}
+
+// CombineSeedXor returns the mnemonic whose entropy is the XOR of the entropy of every part, in any order.
+func (g *SeedGenerator) CombineSeedXor(input common.Language, parts []string) (string, error) {
+	if len(parts) < MinSeedXorParts || len(parts) > MaxSeedXorParts {
+		return "", SeedXorPartsInvalid
+	}
+	var combined []byte
+	for i, part := range parts {
+		entropy, err := g.MnemonicToEntropy(input, part)
+		if err != nil {
+			return "", errors.Wrapf(err, "part #%d", i+1)
+		}
+		if !isSeedXorEntropyLen(len(entropy)) {
+			return "", SeedXorWordCountInvalid
+		}
+		if combined == nil {
+			combined = entropy
+			continue
+		}
+		if len(entropy) != len(combined) {
+			return "", SeedXorPartsMismatch
+		}
+		xorBytes(combined, entropy)
+	}
+	return g.EntropyToMnemonic(combined, input)
+}
+
+func isSeedXorEntropyLen(entropyLen int) bool {
+	return entropyLen*8 == int(Bit128Len) || entropyLen*8 == int(Bit256Len)
+}
+
+// xorBytes sets dst to dst XOR src, both slices have the same length.
+func xorBytes(dst []byte, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
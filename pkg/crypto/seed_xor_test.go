@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitSeedXor(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	for _, mnemonic := range []string{
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold",
+	} {
+		for parts := MinSeedXorParts; parts <= MaxSeedXorParts; parts++ {
+			mnemonics, err := testSeedGenerator.SplitSeedXor(common.English, mnemonic, parts)
+			assert.NoError(t, err)
+			assert.Len(t, mnemonics, parts)
+			for _, part := range mnemonics {
+				assert.NoError(t, testSeedGenerator.ValidateMnemonic(common.English, part))
+			}
+			combined, err := testSeedGenerator.CombineSeedXor(common.English, mnemonics)
+			assert.NoError(t, err)
+			assert.Equal(t, mnemonic, combined)
+			mnemonics[0], mnemonics[parts-1] = mnemonics[parts-1], mnemonics[0]
+			combined, err = testSeedGenerator.CombineSeedXor(common.English, mnemonics)
+			assert.NoError(t, err)
+			assert.Equal(t, mnemonic, combined)
+		}
+	}
+
+	_, err := testSeedGenerator.SplitSeedXor(common.English, "legal winner thank year wave sausage worth useful legal winner thank yellow", 1)
+	assert.Equal(t, SeedXorPartsInvalid, err)
+	_, err = testSeedGenerator.SplitSeedXor(common.English, "legal winner thank year wave sausage worth useful legal winner thank yellow", 5)
+	assert.Equal(t, SeedXorPartsInvalid, err)
+	_, err = testSeedGenerator.SplitSeedXor(common.English,
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal will", 2)
+	assert.Equal(t, SeedXorWordCountInvalid, err)
+}
+
+func TestCombineSeedXor(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	zero := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	ones := "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"
+	combined, err := testSeedGenerator.CombineSeedXor(common.English, []string{zero, ones})
+	assert.NoError(t, err)
+	assert.Equal(t, ones, combined)
+
+	// 7f7f.. XOR ffff.. = 8080..
+	combined, err = testSeedGenerator.CombineSeedXor(common.English,
+		[]string{"legal winner thank year wave sausage worth useful legal winner thank yellow", ones})
+	assert.NoError(t, err)
+	assert.Equal(t, "letter advice cage absurd amount doctor acoustic avoid letter advice cage above", combined)
+
+	_, err = testSeedGenerator.CombineSeedXor(common.English, []string{zero,
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"})
+	assert.Equal(t, SeedXorPartsMismatch, err)
+
+	_, err = testSeedGenerator.CombineSeedXor(common.English, []string{zero, "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo"})
+	var checksumErr *ChecksumMismatchError
+	assert.ErrorAs(t, errors.Cause(err), &checksumErr)
+}
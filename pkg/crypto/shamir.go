@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Shamir secret sharing over GF(256), the Rijndael field x^8 + x^4 + x^3 + x + 1.
+// A share is the value of a random polynomial at its index, the secret is the value at a fixed index.
+
+var (
+	ShamirShareIndexDuplicate = errors.New("Shamir shares must have distinct indexes")
+	ShamirShareLenInvalid     = errors.New("Shamir shares must have the same length")
+	ShamirThresholdInvalid    = errors.New("Shamir threshold must be between 1 and the share count, at most 255 shares")
+
+	gf256Exp [255]byte
+	gf256Log [256]byte
+)
+
+type shamirShare struct {
+	index byte
+	value []byte
+}
+
+func init() {
+	value := byte(1)
+	for i := 0; i < 255; i++ {
+		gf256Exp[i] = value
+		gf256Log[value] = byte(i)
+		// multiply by the generator 3 = x + 1
+		value ^= gf256MulSlow(value, 2)
+	}
+}
+
+func gf256MulSlow(a byte, b byte) byte {
+	var product byte
+	for b > 0 {
+		if b&1 == 1 {
+			product ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return product
+}
+
+func gf256Mul(a byte, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[(int(gf256Log[a])+int(gf256Log[b]))%255]
+}
+
+func gf256Div(a byte, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf256Exp[(int(gf256Log[a])-int(gf256Log[b])+255)%255]
+}
+
+// shamirInterpolate returns the value at x of the polynomial that goes through shares, byte by byte with Lagrange.
+func shamirInterpolate(shares []shamirShare, x byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("Shamir interpolate needs at least one share")
+	}
+	valueLen := len(shares[0].value)
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if seen[share.index] {
+			return nil, ShamirShareIndexDuplicate
+		}
+		seen[share.index] = true
+		if len(share.value) != valueLen {
+			return nil, ShamirShareLenInvalid
+		}
+		if share.index == x {
+			return append([]byte{}, share.value...), nil
+		}
+	}
+	result := make([]byte, valueLen)
+	for i, share := range shares {
+		// basis = prod (x - xj) / (xi - xj), subtraction is xor in GF(256)
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			basis = gf256Mul(basis, gf256Div(x^other.index, share.index^other.index))
+		}
+		for k := range result {
+			result[k] ^= gf256Mul(basis, share.value[k])
+		}
+	}
+	return result, nil
+}
+
+// shamirSplit shares secret, the value at index 0, into shareCount shares of index 1 to shareCount.
+// The first threshold-1 shares are read from source, the other shares are interpolated from them and the secret.
+func shamirSplit(threshold int, shareCount int, secret []byte, source EntropySource) ([]shamirShare, error) {
+	if threshold < 1 || threshold > shareCount || shareCount > 255 {
+		return nil, ShamirThresholdInvalid
+	}
+	shares := make([]shamirShare, 0, shareCount)
+	for i := 1; i < threshold; i++ {
+		value := make([]byte, len(secret))
+		if _, err := source.Read(value); err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{index: byte(i), value: value})
+	}
+	baseShares := append(append([]shamirShare{}, shares...), shamirShare{index: 0, value: secret})
+	for i := threshold; i <= shareCount; i++ {
+		value, err := shamirInterpolate(baseShares, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{index: byte(i), value: value})
+	}
+	return shares, nil
+}
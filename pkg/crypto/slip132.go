@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/pkg/errors"
+)
+
+// SLIP-132 registered HD version bytes, https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+// The version prefix of an extended key tells wallets such as Electrum and Sparrow the script type of its addresses,
+// the key and chain code are the ones of BIP32.
+
+// KeyFormat is the public prefix of a SLIP-132 extended key format, its private prefix is the matching ...prv.
+type KeyFormat string
+
+const (
+	XPub KeyFormat = "xpub" // P2PKH or P2SH, mainnet
+	YPub KeyFormat = "ypub" // P2WPKH in P2SH, mainnet
+	ZPub KeyFormat = "zpub" // P2WPKH, mainnet
+	// YPubMultiSig multisig P2WSH in P2SH, mainnet
+	YPubMultiSig KeyFormat = "Ypub"
+	// ZPubMultiSig multisig P2WSH, mainnet
+	ZPubMultiSig KeyFormat = "Zpub"
+	TPub         KeyFormat = "tpub" // P2PKH or P2SH, testnet
+	UPub         KeyFormat = "upub" // P2WPKH in P2SH, testnet
+	VPub         KeyFormat = "vpub" // P2WPKH, testnet
+	// UPubMultiSig multisig P2WSH in P2SH, testnet
+	UPubMultiSig KeyFormat = "Upub"
+	// VPubMultiSig multisig P2WSH, testnet
+	VPubMultiSig KeyFormat = "Vpub"
+
+	// extendedKeyLen the serialized key length without the 4 bytes checksum
+	extendedKeyLen = 78
+)
+
+var (
+	KeyFormatInvalid          = errors.New("Key format must be xpub, ypub, zpub, Ypub, Zpub, tpub, upub, vpub, Upub or Vpub, or their prv")
+	ExtendedKeyInvalid        = errors.New("Extended key must be a base58 check encoded BIP32 key")
+	ExtendedKeyVersionUnknown = errors.New("Extended key version is not a SLIP-132 registered version")
+
+	slip132Versions = map[KeyFormat]slip132Version{
+		XPub:         {"xprv", [4]byte{0x04, 0x88, 0xad, 0xe4}, [4]byte{0x04, 0x88, 0xb2, 0x1e}},
+		YPub:         {"yprv", [4]byte{0x04, 0x9d, 0x78, 0x78}, [4]byte{0x04, 0x9d, 0x7c, 0xb2}},
+		ZPub:         {"zprv", [4]byte{0x04, 0xb2, 0x43, 0x0c}, [4]byte{0x04, 0xb2, 0x47, 0x46}},
+		YPubMultiSig: {"Yprv", [4]byte{0x02, 0x95, 0xb0, 0x05}, [4]byte{0x02, 0x95, 0xb4, 0x3f}},
+		ZPubMultiSig: {"Zprv", [4]byte{0x02, 0xaa, 0x7a, 0x99}, [4]byte{0x02, 0xaa, 0x7e, 0xd3}},
+		TPub:         {"tprv", [4]byte{0x04, 0x35, 0x83, 0x94}, [4]byte{0x04, 0x35, 0x87, 0xcf}},
+		UPub:         {"uprv", [4]byte{0x04, 0x4a, 0x4e, 0x28}, [4]byte{0x04, 0x4a, 0x52, 0x62}},
+		VPub:         {"vprv", [4]byte{0x04, 0x5f, 0x18, 0xbc}, [4]byte{0x04, 0x5f, 0x1c, 0xf6}},
+		UPubMultiSig: {"Uprv", [4]byte{0x02, 0x42, 0x85, 0xb5}, [4]byte{0x02, 0x42, 0x89, 0xef}},
+		VPubMultiSig: {"Vprv", [4]byte{0x02, 0x57, 0x50, 0x48}, [4]byte{0x02, 0x57, 0x54, 0x83}},
+	}
+
+	// scriptTypeKeyFormat the mainnet and testnet formats of the single key script types,
+	// SLIP-132 registers none for P2TR so it keeps the BIP32 ones.
+	scriptTypeKeyFormat = map[ScriptType][2]KeyFormat{
+		ScriptP2PKH:      {XPub, TPub},
+		ScriptP2SHP2WPKH: {YPub, UPub},
+		ScriptP2WPKH:     {ZPub, VPub},
+		ScriptP2TR:       {XPub, TPub},
+	}
+)
+
+type slip132Version struct {
+	privateName string
+	private     [4]byte
+	public      [4]byte
+}
+
+// ParseKeyFormat returns the format of name, a public (zpub) or private (zprv) prefix. Case matters, zpub and Zpub
+// are different formats.
+func ParseKeyFormat(name string) (KeyFormat, error) {
+	for format, version := range slip132Versions {
+		if name == string(format) || name == version.privateName {
+			return format, nil
+		}
+	}
+	return "", KeyFormatInvalid
+}
+
+// KeyFormatOf returns the format of the keys of scriptType on the network of params: xpub, ypub and zpub on mainnet,
+// tpub, upub and vpub on the test networks.
+func KeyFormatOf(scriptType ScriptType, params *chaincfg.Params) KeyFormat {
+	formats, ok := scriptTypeKeyFormat[scriptType]
+	if !ok {
+		formats = scriptTypeKeyFormat[ScriptP2PKH]
+	}
+	if params.HDPublicKeyID == chaincfg.MainNetParams.HDPublicKeyID {
+		return formats[0]
+	}
+	return formats[1]
+}
+
+// ExtendedKeyFormat returns the format of the version of key, whether it is public or private.
+func ExtendedKeyFormat(key string) (KeyFormat, error) {
+	payload, err := decodeExtendedKey(key)
+	if err != nil {
+		return "", err
+	}
+	format, _, err := versionFormat(payload[:4])
+	return format, err
+}
+
+// ConvertExtendedKey rewrites the version of key, any SLIP-132 format, to format. The key, chain code, depth and
+// fingerprint are kept, so a private key stays private: converting an xprv to zpub gives the zprv.
+func ConvertExtendedKey(key string, format KeyFormat) (string, error) {
+	version, ok := slip132Versions[format]
+	if !ok {
+		return "", KeyFormatInvalid
+	}
+	payload, err := decodeExtendedKey(key)
+	if err != nil {
+		return "", err
+	}
+	_, private, err := versionFormat(payload[:4])
+	if err != nil {
+		return "", err
+	}
+	if private {
+		copy(payload[:4], version.private[:])
+	} else {
+		copy(payload[:4], version.public[:])
+	}
+	return encodeExtendedKey(payload), nil
+}
+
+// versionFormat returns the format of the 4 version bytes and whether they are the private version.
+func versionFormat(versionBytes []byte) (KeyFormat, bool, error) {
+	for format, version := range slip132Versions {
+		if bytes.Equal(versionBytes, version.public[:]) {
+			return format, false, nil
+		}
+		if bytes.Equal(versionBytes, version.private[:]) {
+			return format, true, nil
+		}
+	}
+	return "", false, ExtendedKeyVersionUnknown
+}
+
+// decodeExtendedKey returns the 78 bytes of a base58 check encoded extended key, without the checksum.
+func decodeExtendedKey(key string) ([]byte, error) {
+	decoded := base58.Decode(key)
+	if len(decoded) != extendedKeyLen+4 {
+		return nil, ExtendedKeyInvalid
+	}
+	payload := decoded[:extendedKeyLen]
+	if !bytes.Equal(extendedKeyChecksum(payload), decoded[extendedKeyLen:]) {
+		return nil, ExtendedKeyInvalid
+	}
+	return payload, nil
+}
+
+func encodeExtendedKey(payload []byte) string {
+	return base58.Encode(append(payload, extendedKeyChecksum(payload)...))
+}
+
+// extendedKeyChecksum the first 4 bytes of the double SHA-256 of payload
+func extendedKeyChecksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
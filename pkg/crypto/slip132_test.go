@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	// BIP84 account 0 of networkTestMnemonic, m/84'/0'/0'
+	slip132TestXpub = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+	slip132TestZpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+)
+
+func TestParseKeyFormat(t *testing.T) {
+	format, err := ParseKeyFormat("zprv")
+	assert.NoError(t, err)
+	assert.Equal(t, ZPub, format)
+	format, err = ParseKeyFormat("Zpub")
+	assert.NoError(t, err)
+	assert.Equal(t, ZPubMultiSig, format)
+	_, err = ParseKeyFormat("ZPUB")
+	assert.Equal(t, KeyFormatInvalid, err)
+}
+
+func TestKeyFormatOf(t *testing.T) {
+	assert.Equal(t, XPub, KeyFormatOf(ScriptP2PKH, &chaincfg.MainNetParams))
+	assert.Equal(t, YPub, KeyFormatOf(ScriptP2SHP2WPKH, &chaincfg.MainNetParams))
+	assert.Equal(t, ZPub, KeyFormatOf(ScriptP2WPKH, &chaincfg.MainNetParams))
+	assert.Equal(t, XPub, KeyFormatOf(ScriptP2TR, &chaincfg.MainNetParams))
+	assert.Equal(t, UPub, KeyFormatOf(ScriptP2SHP2WPKH, &chaincfg.TestNet3Params))
+	assert.Equal(t, VPub, KeyFormatOf(ScriptP2WPKH, &SigNetParams))
+	assert.Equal(t, TPub, KeyFormatOf(ScriptP2PKH, &chaincfg.RegressionNetParams))
+}
+
+func TestConvertExtendedKey(t *testing.T) {
+	zpub, err := ConvertExtendedKey(slip132TestXpub, ZPub)
+	assert.NoError(t, err)
+	assert.Equal(t, slip132TestZpub, zpub)
+	format, err := ExtendedKeyFormat(zpub)
+	assert.NoError(t, err)
+	assert.Equal(t, ZPub, format)
+
+	for format, version := range slip132Versions {
+		converted, err := ConvertExtendedKey(slip132TestZpub, format)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(converted, string(format)), converted)
+		xpub, err := ConvertExtendedKey(converted, XPub)
+		assert.NoError(t, err)
+		assert.Equal(t, slip132TestXpub, xpub)
+
+		// a private key stays private
+		xprv := "xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu"
+		converted, err = ConvertExtendedKey(xprv, format)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(converted, version.privateName), converted)
+	}
+
+	_, err = ConvertExtendedKey(slip132TestXpub, "wpub")
+	assert.Equal(t, KeyFormatInvalid, err)
+	_, err = ConvertExtendedKey(slip132TestXpub[:len(slip132TestXpub)-1]+"W", ZPub)
+	assert.Equal(t, ExtendedKeyInvalid, err)
+	_, err = ConvertExtendedKey("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", ZPub)
+	assert.Equal(t, ExtendedKeyInvalid, err)
+}
+
+func TestHDSegWitAddress_Generate_Slip132(t *testing.T) {
+	generator := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList()))
+	generate := func(path string, network Network) *Address {
+		address, err := generator.Generate(map[GenerateArgs]interface{}{
+			InputPath:           path,
+			InputMnemonic:       networkTestMnemonic,
+			InputNetwork:        network,
+			InputSlip132:        true,
+			InputIncludeRootKey: true,
+		})
+		assert.NoError(t, err)
+		return address
+	}
+	address := generate("m/84'/0'/0'/0/0", MainNet)
+	assert.Equal(t, slip132TestZpub, address.AccountPublicKey)
+	assert.True(t, strings.HasPrefix(address.PublicKey, "zpub"))
+	assert.True(t, strings.HasPrefix(address.PrivateKey, "zprv"))
+	assert.True(t, strings.HasPrefix(address.RootPrivateKey, "xprv"))
+
+	assert.True(t, strings.HasPrefix(generate("m/49'/0'/0'/0/0", MainNet).AccountPublicKey, "ypub"))
+	assert.True(t, strings.HasPrefix(generate("m/44'/0'/0'/0/0", MainNet).AccountPublicKey, "xpub"))
+	assert.True(t, strings.HasPrefix(generate("m/86'/0'/0'/0/0", MainNet).AccountPublicKey, "xpub"))
+	assert.True(t, strings.HasPrefix(generate("m/84'/1'/0'/0/0", TestNet3).AccountPublicKey, "vpub"))
+	assert.True(t, strings.HasPrefix(generate("m/49'/1'/0'/0/0", SigNet).PrivateKey, "uprv"))
+}
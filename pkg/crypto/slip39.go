@@ -0,0 +1,483 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+	"strings"
+)
+
+// SLIP-39 Shamir's Secret-Sharing for Mnemonic Codes
+// https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+//
+// The master secret is encrypted with the passphrase by a 4 rounds Feistel network, the encrypted master secret is split
+// into groups and every group secret is split into member shares. A share is rendered as a mnemonic of 10 bits words:
+// identifier(15) extendable(1) iteration exponent(4) | group index(4) group threshold(4) group count(4)
+// member index(4) member threshold(4) | padded share value | RS1024 checksum(30)
+
+const (
+	slip39RadixBits          = 10
+	slip39IdentifierBits     = 15
+	slip39ChecksumWords      = 3
+	slip39MetadataWords      = 4 + slip39ChecksumWords
+	slip39MaxShareCount      = 16
+	slip39DigestLen          = 4
+	slip39DigestIndex        = 254
+	slip39SecretIndex        = 255
+	slip39RoundCount         = 4
+	slip39BaseIterationCount = 10000
+	slip39MaxIterationExp    = 15
+	slip39MinSecretLen       = 16
+	slip39Customization      = "shamir"
+	slip39CustomizationExt   = "shamir_extendable"
+)
+
+var (
+	Slip39SecretLenInvalid     = errors.New("SLIP-39 master secret must be at least 16 bytes and an even number of bytes")
+	Slip39ThresholdInvalid     = errors.New("SLIP-39 thresholds must be between 1 and the share count, share count at most 16")
+	Slip39IterationExpInvalid  = errors.New("SLIP-39 iteration exponent must be between 0 and 15")
+	Slip39ChecksumInvalid      = errors.New("SLIP-39 share checksum is invalid")
+	Slip39PaddingInvalid       = errors.New("SLIP-39 share padding is invalid")
+	Slip39DigestInvalid        = errors.New("SLIP-39 share digest is invalid, the shares do not belong to the same secret")
+	Slip39SharesMismatch       = errors.New("SLIP-39 shares must have the same identifier, iteration exponent and group parameters")
+	Slip39NotEnoughShares      = errors.New("SLIP-39 not enough shares to recover the secret")
+	Slip39ShareWordCountShort  = errors.New("SLIP-39 share must have at least 20 words")
+	Slip39PassphraseInvalid    = errors.New("SLIP-39 passphrase must only contain printable ASCII characters (32 to 126)")
+	slip39Generator            = [10]uint32{0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009, 0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120}
+	slip39WordIndex            = make(map[string]int, len(slip39Words))
+	slip39MinMnemonicWordCount = slip39MetadataWords + (slip39MinSecretLen*8+slip39RadixBits-1)/slip39RadixBits
+)
+
+// Slip39Group is the member threshold and the member count of a group.
+type Slip39Group struct {
+	MemberThreshold int `json:"memberThreshold"`
+	MemberCount     int `json:"memberCount"`
+}
+
+// Slip39Share is a decoded SLIP-39 share mnemonic.
+type Slip39Share struct {
+	Identifier        int
+	Extendable        bool
+	IterationExponent int
+	GroupIndex        int
+	GroupThreshold    int
+	GroupCount        int
+	MemberIndex       int
+	MemberThreshold   int
+	Value             []byte
+}
+
+func init() {
+	for i, word := range slip39Words {
+		slip39WordIndex[word] = i
+	}
+}
+
+// NewSlip39Secret returns a random master secret of bits (at least 128 and a multiple of 16) read from the entropy source.
+func (g *SeedGenerator) NewSlip39Secret(bits int) ([]byte, error) {
+	if bits < slip39MinSecretLen*8 || bits%16 != 0 {
+		return nil, Slip39SecretLenInvalid
+	}
+	secret := make([]byte, bits/8)
+	if _, err := g.entropySource.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// SplitSlip39Secret encrypts masterSecret with passphrase and splits it into SLIP-39 share mnemonics.
+// groupThreshold groups out of groups are needed to recover, and MemberThreshold members out of every used group.
+// The passphrase must be printable ASCII so that every SLIP-39 wallet reproduces the master secret.
+// The encryption runs 10000 * 2^iterationExponent PBKDF2 iterations. The result has one slice of mnemonics per group.
+// The identifier and the random shares are read from the entropy source, the split fails once it fails.
+func (g *SeedGenerator) SplitSlip39Secret(masterSecret []byte, passphrase string, groupThreshold int, groups []Slip39Group,
+	iterationExponent int, extendable bool) ([][]string, error) {
+	if len(masterSecret) < slip39MinSecretLen || len(masterSecret)%2 != 0 {
+		return nil, Slip39SecretLenInvalid
+	}
+	if iterationExponent < 0 || iterationExponent > slip39MaxIterationExp {
+		return nil, Slip39IterationExpInvalid
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) || len(groups) > slip39MaxShareCount {
+		return nil, Slip39ThresholdInvalid
+	}
+	for _, group := range groups {
+		if group.MemberThreshold < 1 || group.MemberThreshold > group.MemberCount || group.MemberCount > slip39MaxShareCount {
+			return nil, Slip39ThresholdInvalid
+		}
+		if group.MemberThreshold == 1 && group.MemberCount > 1 {
+			return nil, errors.Wrap(Slip39ThresholdInvalid, "a 1-of-n group must use 1-of-1 instead")
+		}
+	}
+	if !slip39PassphraseValid(passphrase) {
+		return nil, Slip39PassphraseInvalid
+	}
+	identifierBytes := make([]byte, 2)
+	if _, err := g.entropySource.Read(identifierBytes); err != nil {
+		return nil, err
+	}
+	identifier := int(binary.BigEndian.Uint16(identifierBytes)) & (1<<slip39IdentifierBits - 1)
+	encrypted := slip39Encrypt(masterSecret, passphrase, iterationExponent, identifier, extendable)
+
+	groupShares, err := slip39SplitSecret(groupThreshold, len(groups), encrypted, g.entropySource)
+	if err != nil {
+		return nil, err
+	}
+	mnemonics := make([][]string, len(groups))
+	for i, group := range groups {
+		memberShares, err := slip39SplitSecret(group.MemberThreshold, group.MemberCount, groupShares[i].value, g.entropySource)
+		if err != nil {
+			return nil, err
+		}
+		mnemonics[i] = make([]string, len(memberShares))
+		for j, memberShare := range memberShares {
+			share := Slip39Share{
+				Identifier:        identifier,
+				Extendable:        extendable,
+				IterationExponent: iterationExponent,
+				GroupIndex:        int(groupShares[i].index),
+				GroupThreshold:    groupThreshold,
+				GroupCount:        len(groups),
+				MemberIndex:       int(memberShare.index),
+				MemberThreshold:   group.MemberThreshold,
+				Value:             memberShare.value,
+			}
+			mnemonics[i][j] = share.Mnemonic()
+		}
+	}
+	return mnemonics, nil
+}
+
+// CombineSlip39Shares recovers the master secret from share mnemonics and decrypts it with passphrase.
+// The shares must contain GroupThreshold groups with MemberThreshold distinct members each. A wrong passphrase
+// does not fail, it gives a different master secret, as SLIP-39 requires for plausible deniability.
+// The passphrase must be printable ASCII, it is not normalized.
+func CombineSlip39Shares(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, Slip39NotEnoughShares
+	}
+	if !slip39PassphraseValid(passphrase) {
+		return nil, Slip39PassphraseInvalid
+	}
+	shares := make([]Slip39Share, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		share, err := DecodeSlip39Share(mnemonic)
+		if err != nil {
+			return nil, errors.Wrapf(err, "share #%d", i+1)
+		}
+		shares[i] = *share
+	}
+	first := shares[0]
+	groups := make(map[int][]Slip39Share)
+	for _, share := range shares {
+		if share.Identifier != first.Identifier || share.Extendable != first.Extendable ||
+			share.IterationExponent != first.IterationExponent || share.GroupThreshold != first.GroupThreshold ||
+			share.GroupCount != first.GroupCount || len(share.Value) != len(first.Value) {
+			return nil, Slip39SharesMismatch
+		}
+		if members := groups[share.GroupIndex]; len(members) > 0 && members[0].MemberThreshold != share.MemberThreshold {
+			return nil, Slip39SharesMismatch
+		}
+		duplicate := false
+		for _, member := range groups[share.GroupIndex] {
+			if member.MemberIndex != share.MemberIndex {
+				continue
+			}
+			// a share given twice is kept once, two shares of the same member are an error
+			if !bytes.Equal(member.Value, share.Value) {
+				return nil, errors.Wrapf(ShamirShareIndexDuplicate, "group #%d member #%d", share.GroupIndex+1, share.MemberIndex+1)
+			}
+			duplicate = true
+		}
+		if !duplicate {
+			groups[share.GroupIndex] = append(groups[share.GroupIndex], share)
+		}
+	}
+	if len(groups) < first.GroupThreshold {
+		return nil, errors.Wrapf(Slip39NotEnoughShares, "%d of %d groups", len(groups), first.GroupThreshold)
+	}
+	groupShares := make([]shamirShare, 0, len(groups))
+	for groupIndex, members := range groups {
+		if len(members) < members[0].MemberThreshold {
+			continue
+		}
+		memberShares := make([]shamirShare, 0, len(members))
+		for _, member := range members[:members[0].MemberThreshold] {
+			memberShares = append(memberShares, shamirShare{index: byte(member.MemberIndex), value: member.Value})
+		}
+		groupSecret, err := slip39RecoverSecret(members[0].MemberThreshold, memberShares)
+		if err != nil {
+			return nil, errors.Wrapf(err, "group #%d", groupIndex+1)
+		}
+		groupShares = append(groupShares, shamirShare{index: byte(groupIndex), value: groupSecret})
+	}
+	if len(groupShares) < first.GroupThreshold {
+		return nil, errors.Wrapf(Slip39NotEnoughShares, "%d of %d complete groups", len(groupShares), first.GroupThreshold)
+	}
+	encrypted, err := slip39RecoverSecret(first.GroupThreshold, groupShares[:first.GroupThreshold])
+	if err != nil {
+		return nil, err
+	}
+	return slip39Decrypt(encrypted, passphrase, first.IterationExponent, first.Identifier, first.Extendable), nil
+}
+
+// DecodeSlip39Share parses a share mnemonic and verifies its checksum and padding.
+func DecodeSlip39Share(mnemonic string) (*Slip39Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < slip39MinMnemonicWordCount {
+		return nil, Slip39ShareWordCountShort
+	}
+	indexes := make([]int, len(words))
+	for position, word := range words {
+		index, ok := slip39WordIndex[word]
+		if !ok {
+			return nil, &InvalidWordError{Position: position, Word: word, Language: "slip39"}
+		}
+		indexes[position] = index
+	}
+	share := &Slip39Share{
+		Identifier: (indexes[0]<<slip39RadixBits | indexes[1]) >> 5,
+		Extendable: (indexes[1]>>4)&1 == 1,
+	}
+	if !slip39VerifyChecksum(indexes, share.customization()) {
+		return nil, Slip39ChecksumInvalid
+	}
+	share.IterationExponent = indexes[1] & 0xF
+	groupParams := indexes[2]<<slip39RadixBits | indexes[3]
+	share.GroupIndex = groupParams >> 16
+	share.GroupThreshold = (groupParams>>12)&0xF + 1
+	share.GroupCount = (groupParams>>8)&0xF + 1
+	share.MemberIndex = (groupParams >> 4) & 0xF
+	share.MemberThreshold = groupParams&0xF + 1
+	if share.GroupThreshold > share.GroupCount {
+		return nil, Slip39ThresholdInvalid
+	}
+
+	valueWords := indexes[4 : len(indexes)-slip39ChecksumWords]
+	paddingLen := (slip39RadixBits * len(valueWords)) % 16
+	if paddingLen > 8 {
+		return nil, Slip39PaddingInvalid
+	}
+	value := make([]byte, 0, len(valueWords)*slip39RadixBits/8)
+	accumulator, bits := 0, 0
+	for i, valueWord := range valueWords {
+		accumulator = accumulator<<slip39RadixBits | valueWord
+		bits += slip39RadixBits
+		if i == 0 {
+			if accumulator>>uint(bits-paddingLen) != 0 {
+				return nil, Slip39PaddingInvalid
+			}
+			bits -= paddingLen
+			accumulator &= 1<<uint(bits) - 1
+		}
+		for bits >= 8 {
+			bits -= 8
+			value = append(value, byte(accumulator>>uint(bits)))
+			accumulator &= 1<<uint(bits) - 1
+		}
+	}
+	share.Value = value
+	return share, nil
+}
+
+// Mnemonic renders the share as words of the SLIP-39 word list.
+func (s Slip39Share) Mnemonic() string {
+	extendable := 0
+	if s.Extendable {
+		extendable = 1
+	}
+	identifierExp := s.Identifier<<5 | extendable<<4 | s.IterationExponent
+	groupParams := s.GroupIndex<<16 | (s.GroupThreshold-1)<<12 | (s.GroupCount-1)<<8 | s.MemberIndex<<4 | (s.MemberThreshold - 1)
+	indexes := []int{
+		identifierExp >> slip39RadixBits, identifierExp & (1<<slip39RadixBits - 1),
+		groupParams >> slip39RadixBits, groupParams & (1<<slip39RadixBits - 1),
+	}
+	valueWordCount := (len(s.Value)*8 + slip39RadixBits - 1) / slip39RadixBits
+	valueWords := make([]int, valueWordCount)
+	// the padding bits are the high bits of the first word, so the value is filled from the last word
+	accumulator, bits, position := 0, 0, valueWordCount-1
+	for i := len(s.Value) - 1; i >= 0; i-- {
+		accumulator |= int(s.Value[i]) << uint(bits)
+		bits += 8
+		for bits >= slip39RadixBits {
+			valueWords[position] = accumulator & (1<<slip39RadixBits - 1)
+			accumulator >>= slip39RadixBits
+			bits -= slip39RadixBits
+			position--
+		}
+	}
+	if bits > 0 {
+		valueWords[position] = accumulator
+	}
+	indexes = append(indexes, valueWords...)
+	indexes = append(indexes, slip39CreateChecksum(indexes, s.customization())...)
+	words := make([]string, len(indexes))
+	for i, index := range indexes {
+		words[i] = slip39Words[index]
+	}
+	return strings.Join(words, " ")
+}
+
+func (s Slip39Share) customization() string {
+	if s.Extendable {
+		return slip39CustomizationExt
+	}
+	return slip39Customization
+}
+
+func slip39Polymod(values []int) uint32 {
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 20
+		checksum = (checksum&0xFFFFF)<<slip39RadixBits ^ uint32(value)
+		for i := 0; i < 10; i++ {
+			if (top>>uint(i))&1 == 1 {
+				checksum ^= slip39Generator[i]
+			}
+		}
+	}
+	return checksum
+}
+
+func slip39CustomizationValues(customization string, data []int) []int {
+	values := make([]int, 0, len(customization)+len(data)+slip39ChecksumWords)
+	for _, char := range []byte(customization) {
+		values = append(values, int(char))
+	}
+	return append(values, data...)
+}
+
+func slip39CreateChecksum(data []int, customization string) []int {
+	values := append(slip39CustomizationValues(customization, data), make([]int, slip39ChecksumWords)...)
+	polymod := slip39Polymod(values) ^ 1
+	checksum := make([]int, slip39ChecksumWords)
+	for i := range checksum {
+		checksum[i] = int(polymod>>uint(slip39RadixBits*(slip39ChecksumWords-1-i))) & (1<<slip39RadixBits - 1)
+	}
+	return checksum
+}
+
+func slip39VerifyChecksum(data []int, customization string) bool {
+	return slip39Polymod(slip39CustomizationValues(customization, data)) == 1
+}
+
+func slip39SplitSecret(threshold int, shareCount int, secret []byte, source EntropySource) ([]shamirShare, error) {
+	if threshold < 1 || threshold > shareCount || shareCount > slip39MaxShareCount {
+		return nil, Slip39ThresholdInvalid
+	}
+	shares := make([]shamirShare, 0, shareCount)
+	if threshold == 1 {
+		for i := 0; i < shareCount; i++ {
+			shares = append(shares, shamirShare{index: byte(i), value: append([]byte{}, secret...)})
+		}
+		return shares, nil
+	}
+	randomShareCount := threshold - 2
+	for i := 0; i < randomShareCount; i++ {
+		value := make([]byte, len(secret))
+		if _, err := source.Read(value); err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{index: byte(i), value: value})
+	}
+	randomPart := make([]byte, len(secret)-slip39DigestLen)
+	if _, err := source.Read(randomPart); err != nil {
+		return nil, err
+	}
+	digest := append(slip39Digest(randomPart, secret), randomPart...)
+	baseShares := append(append([]shamirShare{}, shares...),
+		shamirShare{index: slip39DigestIndex, value: digest},
+		shamirShare{index: slip39SecretIndex, value: secret})
+	for i := randomShareCount; i < shareCount; i++ {
+		value, err := shamirInterpolate(baseShares, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{index: byte(i), value: value})
+	}
+	return shares, nil
+}
+
+func slip39RecoverSecret(threshold int, shares []shamirShare) ([]byte, error) {
+	if len(shares) < threshold {
+		return nil, Slip39NotEnoughShares
+	}
+	if threshold == 1 {
+		return shares[0].value, nil
+	}
+	secret, err := shamirInterpolate(shares, slip39SecretIndex)
+	if err != nil {
+		return nil, err
+	}
+	digestShare, err := shamirInterpolate(shares, slip39DigestIndex)
+	if err != nil {
+		return nil, err
+	}
+	digest := slip39Digest(digestShare[slip39DigestLen:], secret)
+	if subtle.ConstantTimeCompare(digest, digestShare[:slip39DigestLen]) != 1 {
+		return nil, Slip39DigestInvalid
+	}
+	return secret, nil
+}
+
+func slip39Digest(randomPart []byte, secret []byte) []byte {
+	mac := hmac.New(sha256.New, randomPart)
+	mac.Write(secret)
+	return mac.Sum(nil)[:slip39DigestLen]
+}
+
+func slip39Salt(identifier int, extendable bool) []byte {
+	if extendable {
+		return []byte{}
+	}
+	return append([]byte(slip39Customization), byte(identifier>>8), byte(identifier))
+}
+
+func slip39RoundFunction(round int, passphrase []byte, iterationExponent int, salt []byte, right []byte) []byte {
+	password := append([]byte{byte(round)}, passphrase...)
+	iterations := (slip39BaseIterationCount << uint(iterationExponent)) / slip39RoundCount
+	return pbkdf2.Key(password, append(append([]byte{}, salt...), right...), iterations, len(right), sha256.New)
+}
+
+func slip39Feistel(input []byte, passphrase string, iterationExponent int, identifier int, extendable bool, rounds []int) []byte {
+	half := len(input) / 2
+	left, right := append([]byte{}, input[:half]...), append([]byte{}, input[half:]...)
+	salt := slip39Salt(identifier, extendable)
+	password := []byte(passphrase)
+	for _, round := range rounds {
+		f := slip39RoundFunction(round, password, iterationExponent, salt, right)
+		for i := range left {
+			left[i] ^= f[i]
+		}
+		left, right = right, left
+	}
+	return append(right, left...)
+}
+
+// slip39PassphraseValid checks that every passphrase character is printable ASCII, from space (32) to tilde (126).
+func slip39PassphraseValid(passphrase string) bool {
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+func slip39Encrypt(masterSecret []byte, passphrase string, iterationExponent int, identifier int, extendable bool) []byte {
+	return slip39Feistel(masterSecret, passphrase, iterationExponent, identifier, extendable, []int{0, 1, 2, 3})
+}
+
+func slip39Decrypt(encrypted []byte, passphrase string, iterationExponent int, identifier int, extendable bool) []byte {
+	return slip39Feistel(encrypted, passphrase, iterationExponent, identifier, extendable, []int{3, 2, 1, 0})
+}
+
+func (s Slip39Share) String() string {
+	return fmt.Sprintf("Slip39Share{id=%d group=%d/%d-of-%d member=%d/%d}", s.Identifier, s.GroupIndex+1,
+		s.GroupThreshold, s.GroupCount, s.MemberIndex+1, s.MemberThreshold)
+}
@@ -0,0 +1,263 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// https://github.com/trezor/python-shamir-mnemonic/blob/master/vectors.json
+// description, passphrase "TREZOR", shares, master secret, the error of the invalid shares
+var slip39Vectors = []struct {
+	description  string
+	mnemonics    []string
+	masterSecret string
+	err          error
+}{
+	{
+		"Valid mnemonic without sharing (128 bits)",
+		[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+		"bb54aac4b89dc868ba37d9cc21b2cece",
+		nil,
+	},
+	{
+		"Mnemonic with invalid checksum (128 bits)",
+		[]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision kidney"},
+		"",
+		Slip39ChecksumInvalid,
+	},
+	{
+		"Mnemonic with invalid padding (128 bits)",
+		[]string{"duckling enlarge academic academic email result length solution fridge kidney coal piece deal husband erode duke ajar music cargo fitness"},
+		"",
+		Slip39PaddingInvalid,
+	},
+	{
+		"Basic sharing 2-of-3 (128 bits)",
+		[]string{
+			"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+			"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+		},
+		"b43ceb7e57a0ea8766221624d01b0864",
+		nil,
+	},
+	{
+		"Mnemonics with different identifiers (128 bits)",
+		[]string{
+			"adequate smoking academic acid debut wine petition glen cluster slow rhyme slow simple epidemic rumor junk tracks treat olympic tolerate",
+			"adequate stay academic agency agency formal party ting frequent learn upstairs remember smear leaf damage anatomy ladle market hush corner",
+		},
+		"",
+		Slip39SharesMismatch,
+	},
+	{
+		"Mnemonics with different iteration exponents (128 bits)",
+		[]string{
+			"peasant leaves academic acid desert exact olympic math alive axle trial tackle drug deny decent smear dominant desert bucket remind",
+			"peasant leader academic agency cultural blessing percent network envelope medal junk primary human pumps jacket fragment payroll ticket evoke voice",
+		},
+		"",
+		Slip39SharesMismatch,
+	},
+	{
+		"Insufficient number of groups (128 bits)",
+		[]string{
+			"liberty category beard echo animal fawn temple briefing math username various wolf aviation fancy visual holy thunder yelp helpful payment",
+			"liberty category beard email beyond should fancy romp founder easel pink holy hairy romp loyalty material victim owner toxic custody",
+		},
+		"",
+		Slip39NotEnoughShares,
+	},
+	{
+		"Mnemonics with mismatching group counts (128 bits)",
+		[]string{
+			"average senior academic leaf broken teacher expect surface hour capture obesity desire negative dynamic dominant pistol mineral mailman iris aide",
+			"average senior academic agency curious pants blimp spew clothes slice script dress wrap firm shaft regular slavery negative theater roster",
+		},
+		"",
+		Slip39SharesMismatch,
+	},
+	{
+		"Mnemonics with greater group threshold than group counts (128 bits)",
+		[]string{
+			"music husband acrobat acid artist finance center either graduate swimming object bike medical clothes station aspect spider maiden bulb welcome",
+			"music husband acrobat agency advance hunting bike corner density careful material civil evil tactics remind hawk discuss hobo voice rainbow",
+		},
+		"",
+		Slip39ThresholdInvalid,
+	},
+	{
+		"Threshold number of groups and members in each group (128 bits, case 1)",
+		[]string{
+			"eraser senior beard romp adorn nuclear spill corner cradle style ancient family general leader ambition exchange unusual garlic promise voice",
+			"eraser senior ceramic snake clay various huge numb argue hesitate auction category timber browser greatest hanger petition script leaf pickup",
+			"eraser senior ceramic shaft dynamic become junior wrist silver peasant force math alto coal amazing segment yelp velvet image paces",
+			"eraser senior ceramic round column hawk trust auction smug shame alive greatest sheriff living perfect corner chest sled fumes adequate",
+		},
+		"7c3397a292a5941682d7a4ae2d898d11",
+		nil,
+	},
+	{
+		"Threshold number of groups and members in each group (128 bits, case 2)",
+		[]string{
+			"eraser senior decision scared cargo theory device idea deliver modify curly include pancake both news skin realize vitamins away join",
+			"eraser senior decision roster beard treat identify grumpy salt index fake aviation theater cubic bike cause research dragon emphasis counter",
+			"eraser senior ceramic shaft dynamic become junior wrist silver peasant force math alto coal amazing segment yelp velvet image paces",
+			"eraser senior ceramic snake clay various huge numb argue hesitate auction category timber browser greatest hanger petition script leaf pickup",
+			"eraser senior ceramic round column hawk trust auction smug shame alive greatest sheriff living perfect corner chest sled fumes adequate",
+		},
+		"7c3397a292a5941682d7a4ae2d898d11",
+		nil,
+	},
+	{
+		"Valid mnemonic without sharing (256 bits)",
+		[]string{"theory painting academic academic armed sweater year military elder discuss acne wildlife boring employer fused large satoshi bundle carbon diagnose anatomy hamster leaves tracks paces beyond phantom capital marvel lips brave detect luck"},
+		"989baf9dcaad5b10ca33dfd8cc75e42477025dce88ae83e75a230086a0e00e92",
+		nil,
+	},
+	{
+		"Basic sharing 2-of-3 (256 bits)",
+		[]string{
+			"humidity disease academic always aluminum jewelry energy woman receiver strategy amuse duckling lying evidence network walnut tactics forget hairy rebound impulse brother survive clothes stadium mailman rival ocean reward venture always armed unwrap",
+			"humidity disease academic agency actress jacket gross physics cylinder solution fake mortgage benefit public busy prepare sharp friar change work slow purchase ruler again tricycle involve viral wireless mixture anatomy desert cargo upgrade",
+		},
+		"c938b319067687e990e05e0da0ecce1278f75ff58d9853f19dcaeed5de104aae",
+		nil,
+	},
+	{
+		"Valid extendable mnemonic without sharing (128 bits)",
+		[]string{"testify swimming academic academic column loyalty smear include exotic bedroom exotic wrist lobe cover grief golden smart junior estimate learn"},
+		"1679b4516e0ee5954351d288a838f45e",
+		nil,
+	},
+	{
+		"Extendable basic sharing 2-of-3 (128 bits)",
+		[]string{
+			"enemy favorite academic acid cowboy phrase havoc level response walnut budget painting inside trash adjust froth kitchen learn tidy punish",
+			"enemy favorite academic always academic sniff script carpet romp kind promise scatter center unfair training emphasis evening belong fake enforce",
+		},
+		"48b1a4b80b8c209ad42c33672bdaa428",
+		nil,
+	},
+	{
+		"Valid extendable mnemonic without sharing (256 bits)",
+		[]string{"impulse calcium academic academic alcohol sugar lyrics pajamas column facility finance tension extend space birthday rainbow swimming purple syndrome facility trial warn duration snapshot shadow hormone rhyme public spine counter easy hawk album"},
+		"8340611602fe91af634a5f4608377b5235fa2d757c51d720c0c7656249a3035f",
+		nil,
+	},
+	{
+		"Extendable basic sharing 2-of-3 (256 bits)",
+		[]string{
+			"western apart academic always artist resident briefing sugar woman oven coding club ajar merit pecan answer prisoner artist fraction amount desktop mild false necklace muscle photo wealthy alpha category unwrap spew losing making",
+			"western apart academic acid answer ancient auction flip image penalty oasis beaver multiple thunder problem switch alive heat inherit superior teaspoon explain blanket pencil numb lend punish endless aunt garlic humidity kidney observe",
+		},
+		"8dc652d6d6cd370d8c963141f6d79ba440300f25c467302c1d966bff8f62300d",
+		nil,
+	},
+}
+
+func TestCombineSlip39Shares_Vectors(t *testing.T) {
+	for _, vector := range slip39Vectors {
+		secret, err := CombineSlip39Shares(vector.mnemonics, "TREZOR")
+		if vector.err != nil {
+			assert.ErrorIs(t, err, vector.err, vector.description)
+			continue
+		}
+		assert.NoError(t, err, vector.description)
+		assert.Equal(t, vector.masterSecret, hex.EncodeToString(secret), vector.description)
+	}
+}
+
+// Two shares of the same member: the second share of the 2-of-3 vector re-encoded with the member index of the first.
+// A share given twice is kept once.
+func TestCombineSlip39Shares_DuplicateMemberIndex(t *testing.T) {
+	basic := slip39Vectors[3].mnemonics
+	first, err := DecodeSlip39Share(basic[0])
+	assert.NoError(t, err)
+	second, err := DecodeSlip39Share(basic[1])
+	assert.NoError(t, err)
+	second.MemberIndex = first.MemberIndex
+	_, err = CombineSlip39Shares([]string{basic[0], second.Mnemonic()}, "TREZOR")
+	assert.ErrorIs(t, err, ShamirShareIndexDuplicate)
+
+	secret, err := CombineSlip39Shares([]string{basic[0], basic[0], basic[1]}, "TREZOR")
+	assert.NoError(t, err)
+	assert.Equal(t, slip39Vectors[3].masterSecret, hex.EncodeToString(secret))
+}
+
+func TestSplitSlip39Secret(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	masterSecret, _ := hex.DecodeString("bb54aac4b89dc868ba37d9cc21b2cece0c0c4b2e05e6dd8da1cd2c9fa24d6a31")
+	groups := []Slip39Group{{MemberThreshold: 1, MemberCount: 1}, {MemberThreshold: 2, MemberCount: 3}, {MemberThreshold: 3, MemberCount: 5}}
+	mnemonics, err := testSeedGenerator.SplitSlip39Secret(masterSecret, "TREZOR", 2, groups, 0, true)
+	assert.NoError(t, err)
+	assert.Len(t, mnemonics, 3)
+	assert.Len(t, mnemonics[2], 5)
+
+	secret, err := CombineSlip39Shares([]string{mnemonics[0][0], mnemonics[2][4], mnemonics[2][0], mnemonics[2][2]}, "TREZOR")
+	assert.NoError(t, err)
+	assert.Equal(t, masterSecret, secret)
+
+	secret, err = CombineSlip39Shares([]string{mnemonics[1][2], mnemonics[2][1], mnemonics[1][0], mnemonics[2][3], mnemonics[2][4]}, "TREZOR")
+	assert.NoError(t, err)
+	assert.Equal(t, masterSecret, secret)
+
+	secret, err = CombineSlip39Shares([]string{mnemonics[0][0], mnemonics[1][0], mnemonics[1][1]}, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, masterSecret, secret)
+
+	_, err = CombineSlip39Shares([]string{mnemonics[0][0], mnemonics[1][0]}, "TREZOR")
+	assert.ErrorIs(t, err, Slip39NotEnoughShares)
+
+	_, err = testSeedGenerator.SplitSlip39Secret(masterSecret, "", 1, []Slip39Group{{MemberThreshold: 1, MemberCount: 2}}, 0, false)
+	assert.ErrorIs(t, err, Slip39ThresholdInvalid)
+	_, err = testSeedGenerator.SplitSlip39Secret(masterSecret[:15], "", 1, []Slip39Group{{MemberThreshold: 1, MemberCount: 1}}, 0, false)
+	assert.Equal(t, Slip39SecretLenInvalid, err)
+
+	_, err = NewSeedGenerator(common.GetWordList(), constantEntropySource(0)).SplitSlip39Secret(masterSecret, "", 2, groups, 0, true)
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+
+	// the passphrase is printable ASCII, not normalized
+	_, err = testSeedGenerator.SplitSlip39Secret(masterSecret, "pässwörd", 1, []Slip39Group{{MemberThreshold: 1, MemberCount: 1}}, 0, false)
+	assert.Equal(t, Slip39PassphraseInvalid, err)
+	_, err = CombineSlip39Shares(mnemonics[0], "TREZOR\n")
+	assert.Equal(t, Slip39PassphraseInvalid, err)
+}
+
+func TestDecodeSlip39Share(t *testing.T) {
+	for _, vector := range slip39Vectors {
+		for _, mnemonic := range vector.mnemonics {
+			share, err := DecodeSlip39Share(mnemonic)
+			if err != nil {
+				// the other invalid vectors are valid shares that do not combine
+				assert.Equal(t, vector.err, err, vector.description)
+				continue
+			}
+			assert.Equal(t, mnemonic, share.Mnemonic(), vector.description)
+		}
+	}
+}
+
+func TestNewSlip39Secret(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+	secret, err := testSeedGenerator.NewSlip39Secret(256)
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+	_, err = testSeedGenerator.NewSlip39Secret(120)
+	assert.Equal(t, Slip39SecretLenInvalid, err)
+	_, err = NewSeedGenerator(common.GetWordList(), constantEntropySource(0)).NewSlip39Secret(128)
+	assert.Equal(t, EntropySourceUnhealthy, errors.Cause(err))
+}
+
+func TestSlip39MasterSecretAsInputSeed(t *testing.T) {
+	secret, err := CombineSlip39Shares(slip39Vectors[0].mnemonics, "TREZOR")
+	assert.NoError(t, err)
+	address, err := NewHDSegWitAddress(nil).Generate(map[GenerateArgs]interface{}{
+		InputSeed: hex.EncodeToString(secret),
+		InputPath: "m/84'/0'/0'/0/0",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(secret), address.Seed)
+}
@@ -0,0 +1,173 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// BIP86 single key Taproot outputs, https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki
+// The output key is the BIP341 tweak of the x-only internal key without a script tree,
+// Q = P + int(tagged_hash("TapTweak", x(P)))G, encoded as a witness version 1 bech32m address (BIP350).
+
+const (
+	TaprootAddressGenerator = "TaprootAddressGenerator"
+	taprootWitnessVersion   = 1
+	bech32mConstant         = 0x2bc830a3
+	bech32Charset           = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var (
+	TaprootTweakInvalid        = errors.New("Taproot tweak is not a valid scalar")
+	TaprootElectrumSeedInvalid = errors.New("Electrum seeds have no Taproot derivation")
+)
+
+// TaprootAddress generates BIP86 P2TR addresses, usually of the m/86'/coin'/account'/change/index paths.
+// It takes the arguments of HDSegWitAddress and always builds a Taproot output.
+type TaprootAddress struct {
+	hdSegWitAddress HDSegWitAddress
+}
+
+func NewTaprootAddress(seedGenerator *SeedGenerator) TaprootAddress {
+	return TaprootAddress{
+		hdSegWitAddress: NewHDSegWitAddress(seedGenerator),
+	}
+}
+
+func (t TaprootAddress) Generate(args map[GenerateArgs]interface{}) (*Address, error) {
+	if args == nil || len(args) == 0 {
+		return nil, ArgsMustBeNotNull
+	}
+	taprootArgs := make(map[GenerateArgs]interface{}, len(args)+1)
+	for key, value := range args {
+		taprootArgs[key] = value
+	}
+	taprootArgs[InputScriptType] = ScriptP2TR
+	return t.hdSegWitAddress.Generate(taprootArgs)
+}
+
+// taprootAddress is a witness version 1 output, btcutil v1.0.2 has no bech32m Address.
+type taprootAddress struct {
+	hrp       string
+	outputKey []byte
+}
+
+func (a *taprootAddress) String() string {
+	return a.EncodeAddress()
+}
+
+func (a *taprootAddress) EncodeAddress() string {
+	address, err := encodeSegWitAddressV1(a.hrp, a.outputKey)
+	if err != nil {
+		return ""
+	}
+	return address
+}
+
+func (a *taprootAddress) ScriptAddress() []byte {
+	return a.outputKey
+}
+
+func (a *taprootAddress) IsForNet(params *chaincfg.Params) bool {
+	return a.hrp == params.Bech32HRPSegwit
+}
+
+// newTaprootAddress builds the BIP86 address of the compressed public key.
+func newTaprootAddress(publicKey []byte, params *chaincfg.Params) (*taprootAddress, error) {
+	outputKey, err := taprootOutputKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &taprootAddress{hrp: params.Bech32HRPSegwit, outputKey: outputKey}, nil
+}
+
+// taprootOutputKey returns the x-only output key of the compressed internal public key, tweaked without a script tree.
+func taprootOutputKey(publicKey []byte) ([]byte, error) {
+	curve := btcec.S256()
+	internalKey, err := btcec.ParsePubKey(publicKey, curve)
+	if err != nil {
+		return nil, err
+	}
+	// BIP340 x-only keys stand for the point with the even y
+	x, y := internalKey.X, internalKey.Y
+	if y.Bit(0) == 1 {
+		y = new(big.Int).Sub(curve.P, y)
+	}
+	tweak := taggedHash("TapTweak", xOnly(x))
+	if new(big.Int).SetBytes(tweak).Cmp(curve.N) >= 0 {
+		return nil, TaprootTweakInvalid
+	}
+	tweakX, tweakY := curve.ScalarBaseMult(tweak)
+	outputX, _ := curve.Add(x, y, tweakX, tweakY)
+	return xOnly(outputX), nil
+}
+
+// taggedHash is the BIP340 SHA256(SHA256(tag) || SHA256(tag) || msg).
+func taggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	hash := sha256.New()
+	hash.Write(tagHash[:])
+	hash.Write(tagHash[:])
+	for _, part := range msg {
+		hash.Write(part)
+	}
+	return hash.Sum(nil)
+}
+
+// xOnly is the 32 bytes big endian x coordinate.
+func xOnly(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, 32))
+}
+
+// encodeSegWitAddressV1 encodes a witness version 1 program with the bech32m checksum of BIP350.
+func encodeSegWitAddressV1(hrp string, program []byte) (string, error) {
+	converted, err := bech32.ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data := append([]byte{taprootWitnessVersion}, converted...)
+	values := append(bech32HrpExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ bech32mConstant
+
+	var address strings.Builder
+	address.WriteString(hrp)
+	address.WriteString("1")
+	for _, value := range data {
+		address.WriteByte(bech32Charset[value])
+	}
+	for i := 0; i < 6; i++ {
+		address.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return address.String(), nil
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ uint32(value)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				checksum ^= generator[i]
+			}
+		}
+	}
+	return checksum
+}
@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"encoding/hex"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki#test-vectors
+var bip86Dict = []struct {
+	path        string
+	internalKey string
+	outputKey   string
+	address     string
+}{
+	{"m/86'/0'/0'/0/0", "cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+		"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+		"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	{"m/86'/0'/0'/0/1", "83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+		"a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb",
+		"bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+	{"m/86'/0'/0'/1/0", "399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef",
+		"882d74e5d0572d5a816cef0041a96b6c1de832f6f9676d9605c44d5e9a97d3dc",
+		"bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7"},
+}
+
+func TestTaprootAddress_Generate(t *testing.T) {
+	generator := NewTaprootAddress(GetSeedGenerator(common.GetWordList()))
+	for _, vector := range bip86Dict {
+		address, err := generator.Generate(map[GenerateArgs]interface{}{
+			InputMnemonic: networkTestMnemonic,
+			InputPath:     vector.path,
+		})
+		assert.NoError(t, err, vector.path)
+		assert.Equal(t, vector.address, address.Address, vector.path)
+		assert.Equal(t, string(ScriptP2TR), address.ScriptType)
+
+		seed, _ := hex.DecodeString(address.Seed)
+		_, _, child, err := deriveKey(seed, vector.path)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.internalKey, hex.EncodeToString(child.PublicKey().Key[1:]))
+		outputKey, err := taprootOutputKey(child.PublicKey().Key)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.outputKey, hex.EncodeToString(outputKey))
+	}
+
+	// the purpose 86 selects Taproot in HDSegWitAddress as well
+	address, err := NewHDSegWitAddress(GetSeedGenerator(common.GetWordList())).Generate(map[GenerateArgs]interface{}{
+		InputMnemonic: networkTestMnemonic,
+		InputPath:     bip86Dict[0].path,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, bip86Dict[0].address, address.Address)
+	assert.True(t, common.IsInvalidPath(bip86Dict[0].path))
+
+	_, err = generator.Generate(map[GenerateArgs]interface{}{
+		InputMnemonic:       electrumSeedDict[0].mnemonic,
+		InputMnemonicFormat: MnemonicElectrum,
+		InputPath:           bip86Dict[0].path,
+	})
+	assert.Equal(t, TaprootElectrumSeedInvalid, err)
+}
+
+// https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki#test-vectors-for-v0-v16-native-segregated-witness-addresses
+func TestEncodeSegWitAddressV1(t *testing.T) {
+	program, _ := hex.DecodeString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	address, err := encodeSegWitAddressV1("bc", program)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", address)
+
+	// testnet and regtest only change the HRP
+	address, err = encodeSegWitAddressV1("tb", program)
+	assert.NoError(t, err)
+	assert.Equal(t, "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47zagq", address)
+}
@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"math"
+	"strings"
+	"unicode"
+)
+
+type UserEntropyFormat string
+
+const (
+	// UserEntropyDice base 6 dice rolls, the digits 1 to 6. Every roll carries log2(6) ~ 2.58 bits.
+	UserEntropyDice UserEntropyFormat = "dice"
+	// UserEntropyCoin binary coin flips, the digits 0 and 1. Every flip carries 1 bit.
+	UserEntropyCoin UserEntropyFormat = "coin"
+	// UserEntropyHex hex digits. Every digit carries 4 bits.
+	UserEntropyHex UserEntropyFormat = "hex"
+)
+
+var (
+	UserEntropyFormatInvalid = errors.New("User entropy format must be dice, coin or hex")
+	userEntropyDigits        = map[UserEntropyFormat]string{
+		UserEntropyDice: "123456",
+		UserEntropyCoin: "01",
+		UserEntropyHex:  "0123456789abcdef",
+	}
+)
+
+// InvalidUserEntropyError reports a character that is not a digit of the user entropy format.
+type InvalidUserEntropyError struct {
+	Format   UserEntropyFormat
+	Position int
+	Char     rune
+}
+
+func (e *InvalidUserEntropyError) Error() string {
+	return fmt.Sprintf("User entropy #%d %q is not a %s digit", e.Position+1, e.Char, e.Format)
+}
+
+// InsufficientEntropyError reports a user entropy that carries less bits than the mnemonic word count needs.
+type InsufficientEntropyError struct {
+	Format   UserEntropyFormat
+	Required int
+	Actual   int
+	Digits   int
+}
+
+func (e *InsufficientEntropyError) Error() string {
+	return fmt.Sprintf("User entropy carries %d bits, %d bits are required, at least %d %s digits",
+		e.Actual, e.Required, e.Digits, e.Format)
+}
+
+// UserEntropyBits returns the entropy bits carried by digits digits of format, rounded down.
+func UserEntropyBits(format UserEntropyFormat, digits int) int {
+	return int(math.Floor(float64(digits) * math.Log2(float64(len(userEntropyDigits[format])))))
+}
+
+// UserEntropyMnemonic generates a count words mnemonic from dice rolls, coin flips or hex digits instead of crypto/rand.
+// Whitespace is ignored. The input must carry at least the entropy bits of count.
+// Coin flips and hex digits of exactly the entropy bits are the entropy as is, so the mnemonic can be checked by hand.
+// Dice rolls, as Coldcard does, and longer inputs are hashed with SHA256 and truncated to the entropy bits.
+// With mixSystem, the SHA256 of the user entropy and of as many bytes of the generator entropy source is used instead,
+// the mnemonic is then not reproducible from the input.
+func (g *SeedGenerator) UserEntropyMnemonic(input common.Language, count WordCount, format UserEntropyFormat,
+	value string, mixSystem bool) (string, error) {
+	digits, ok := userEntropyDigits[format]
+	if !ok {
+		return "", UserEntropyFormatInvalid
+	}
+	seedLen, ok := mnemonicLen[count]
+	if !ok {
+		return "", unSupportWordLenError
+	}
+	normalized := make([]rune, 0, len(value))
+	for position, char := range []rune(strings.ToLower(value)) {
+		if unicode.IsSpace(char) {
+			continue
+		}
+		if !strings.ContainsRune(digits, char) {
+			return "", &InvalidUserEntropyError{Format: format, Position: position, Char: char}
+		}
+		normalized = append(normalized, char)
+	}
+	bits := UserEntropyBits(format, len(normalized))
+	if bits < int(seedLen) {
+		required := int(math.Ceil(float64(seedLen) / math.Log2(float64(len(digits)))))
+		return "", &InsufficientEntropyError{Format: format, Required: int(seedLen), Actual: bits, Digits: required}
+	}
+	entropy, err := userEntropyBytes(format, string(normalized), seedLen)
+	if err != nil {
+		return "", err
+	}
+	if mixSystem {
+		systemEntropy := make([]byte, len(entropy))
+		if _, err := g.entropySource.Read(systemEntropy); err != nil {
+			return "", err
+		}
+		mixed := sha256.Sum256(append(entropy, systemEntropy...))
+		entropy = mixed[:len(entropy)]
+	}
+	return g.EntropyToMnemonic(entropy, input)
+}
+
+func userEntropyBytes(format UserEntropyFormat, normalized string, seedLen SeedLen) ([]byte, error) {
+	if format == UserEntropyDice || UserEntropyBits(format, len(normalized)) != int(seedLen) {
+		hash := sha256.Sum256([]byte(normalized))
+		return hash[:seedLen/8], nil
+	}
+	if format == UserEntropyHex {
+		return hex.DecodeString(normalized)
+	}
+	// seedLen is a multiple of 8, every flip is the next bit of the entropy, most significant first
+	entropy := make([]byte, len(normalized)/8)
+	for i, flip := range normalized {
+		if flip == '1' {
+			entropy[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return entropy, nil
+}
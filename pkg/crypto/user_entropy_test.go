@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	common "github.com/pzhenzhou/crypto-prototype/pkg"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestUserEntropyMnemonic(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+
+	mnemonic, err := testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyHex, "7F7F7F7F 7F7F7F7F 7F7F7F7F 7F7F7F7F", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "legal winner thank year wave sausage worth useful legal winner thank yellow", mnemonic)
+
+	mnemonic, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyCoin, strings.Repeat("10000000", 16), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "letter advice cage absurd amount doctor acoustic avoid letter advice cage above", mnemonic)
+
+	// the flips of 9e885d952ad362caeb4efe34a8e91bd2, most significant bit first
+	flips := "10011110 10001000 01011101 10010101 00101010 11010011 01100010 11001010 " +
+		"11101011 01001110 11111110 00110100 10101000 11101001 00011011 11010010"
+	mnemonic, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyCoin, flips, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic", mnemonic)
+
+	dice := "16253443162534431625344316253443162534431625344316"
+	mnemonic, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyDice, dice, false)
+	assert.NoError(t, err)
+	expected, _ := hex.DecodeString("157aa312510494ade5fa3f6268645fda")
+	expectedMnemonic, _ := testSeedGenerator.EntropyToMnemonic(expected, common.English)
+	assert.Equal(t, expectedMnemonic, mnemonic)
+
+	dice = strings.Repeat("6543216", 15)[:100]
+	mnemonic, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word24, UserEntropyDice, dice, false)
+	assert.NoError(t, err)
+	expected, _ = hex.DecodeString("552c8aeae946fff10a3062067e41ac4a99d8968564d5e7620f70007cdbaaa85e")
+	expectedMnemonic, _ = testSeedGenerator.EntropyToMnemonic(expected, common.English)
+	assert.Equal(t, expectedMnemonic, mnemonic)
+
+	longer := strings.Repeat("7f", 20)
+	mnemonic, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyHex, longer, false)
+	assert.NoError(t, err)
+	hash := sha256.Sum256([]byte(longer))
+	expectedMnemonic, _ = testSeedGenerator.EntropyToMnemonic(hash[:16], common.English)
+	assert.Equal(t, expectedMnemonic, mnemonic)
+}
+
+func TestUserEntropyMnemonicMixSystem(t *testing.T) {
+	testSeedGenerator := NewSeedGenerator(common.GetWordList(), NewDeterministicEntropySource([]byte("mix")))
+	mnemonic, err := testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyHex, strings.Repeat("00", 16), true)
+	assert.NoError(t, err)
+	systemEntropy := make([]byte, 16)
+	_, _ = NewDeterministicEntropySource([]byte("mix")).Read(systemEntropy)
+	mixed := sha256.Sum256(append(make([]byte, 16), systemEntropy...))
+	expectedMnemonic, _ := testSeedGenerator.EntropyToMnemonic(mixed[:16], common.English)
+	assert.Equal(t, expectedMnemonic, mnemonic)
+	assert.NotEqual(t, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", mnemonic)
+}
+
+func TestUserEntropyMnemonicInvalid(t *testing.T) {
+	testSeedGenerator := GetSeedGenerator(common.GetWordList())
+
+	_, err := testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyDice, strings.Repeat("1", 49), false)
+	var insufficientErr *InsufficientEntropyError
+	if assert.ErrorAs(t, err, &insufficientErr) {
+		assert.Equal(t, 128, insufficientErr.Required)
+		assert.Equal(t, 126, insufficientErr.Actual)
+		assert.Equal(t, 50, insufficientErr.Digits)
+	}
+	_, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word24, UserEntropyDice, strings.Repeat("1", 99), false)
+	if assert.ErrorAs(t, err, &insufficientErr) {
+		assert.Equal(t, 100, insufficientErr.Digits)
+	}
+	_, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyCoin, strings.Repeat("1", 127), false)
+	assert.ErrorAs(t, err, &insufficientErr)
+
+	_, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, UserEntropyDice, strings.Repeat("1", 49)+"7", false)
+	var invalidErr *InvalidUserEntropyError
+	if assert.ErrorAs(t, err, &invalidErr) {
+		assert.Equal(t, 49, invalidErr.Position)
+		assert.Equal(t, '7', invalidErr.Char)
+	}
+	_, err = testSeedGenerator.UserEntropyMnemonic(common.English, Word12, "base64", "AAAA", false)
+	assert.Equal(t, UserEntropyFormatInvalid, err)
+	_, err = testSeedGenerator.UserEntropyMnemonic(common.English, 13, UserEntropyHex, strings.Repeat("00", 16), false)
+	assert.Equal(t, unSupportWordLenError, err)
+}
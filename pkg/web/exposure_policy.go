@@ -0,0 +1,223 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/pzhenzhou/crypto-prototype/pkg/crypto"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AddressField is a group of crypto.Address fields whose exposure the policy controls.
+type AddressField string
+
+const (
+	FieldAddress    AddressField = "address"
+	FieldPublicKey  AddressField = "publicKey"
+	FieldPrivateKey AddressField = "privateKey"
+	FieldMnemonic   AddressField = "mnemonic"
+	FieldSeed       AddressField = "seed"
+
+	// ApiKeyHeader carries the API key of a request.
+	ApiKeyHeader = "X-API-Key"
+	devEnv       = "dev"
+	prodEnv      = "prod"
+)
+
+var (
+	allAddressFields = []AddressField{FieldAddress, FieldPublicKey, FieldPrivateKey, FieldMnemonic, FieldSeed}
+	// defaultEnvironmentFields applies when the policy does not name the environment, prod withholds every secret.
+	defaultEnvironmentFields = map[string][]AddressField{
+		devEnv:  allAddressFields,
+		prodEnv: {FieldAddress, FieldPublicKey},
+	}
+	exposurePolicy = &ExposurePolicy{}
+
+	// secretRouteFields the field groups of the secrets that the other routes return, the address routes filter their
+	// Address instead. /bip85 and /extended_key/convert depend on the request and authorize in their handler.
+	secretRouteFields = map[string][]AddressField{
+		"/mnemonic":                   {FieldMnemonic},
+		"/mnemonic/entropy":           {FieldMnemonic},
+		"/mnemonic/from_entropy":      {FieldMnemonic},
+		"/mnemonic/from_user_entropy": {FieldMnemonic},
+		"/mnemonic/expand":            {FieldMnemonic},
+		"/mnemonic/final_words":       {FieldMnemonic},
+		"/mnemonic/translate":         {FieldMnemonic},
+		"/mnemonic/split":             {FieldMnemonic},
+		"/mnemonic/combine":           {FieldMnemonic},
+		"/slip39/split":               {FieldMnemonic},
+		"/slip39/combine":             {FieldSeed},
+		"/seedxor/split":              {FieldMnemonic},
+		"/seedxor/combine":            {FieldMnemonic},
+		"/electrum/mnemonic":          {FieldMnemonic},
+		"/aezeed/mnemonic":            {FieldMnemonic},
+		"/aezeed/decode":              {FieldSeed},
+	}
+)
+
+// FieldNotAllowedError reports a field, asked for with include or returned by a secret route, that the exposure policy
+// withholds from the request.
+type FieldNotAllowedError struct {
+	Field AddressField
+}
+
+func (e *FieldNotAllowedError) Error() string {
+	return fmt.Sprintf("the exposure policy does not allow the %s field", e.Field)
+}
+
+// ExposurePolicy lists the Address fields a response may carry, per environment (dev or prod) and per API key.
+// The routes that return other secrets need the field group of the secret, e.g. mnemonic for /mnemonic/split
+// and seed for /aezeed/decode, see secretRouteFields. An API key entry wins over the environment entry. ApiKeys is keyed by the hex SHA-256 of the API key,
+// so that the policy file holds no usable key. A request narrows the allowed fields with ?include=.
+//
+//	{
+//	    "environments": {"prod": ["address", "publicKey"]},
+//	    "apiKeys": {"<sha256 of the key>": ["address", "publicKey", "privateKey", "mnemonic", "seed"]}
+//	}
+type ExposurePolicy struct {
+	Environments map[string][]AddressField `json:"environments"`
+	ApiKeys      map[string][]AddressField `json:"apiKeys"`
+	prod         bool
+}
+
+// LoadExposurePolicy reads the JSON policy of path.
+func LoadExposurePolicy(path string) (*ExposurePolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := &ExposurePolicy{}
+	if err := json.Unmarshal(content, policy); err != nil {
+		return nil, errors.Wrapf(err, "exposure policy %s", path)
+	}
+	for environment, fields := range policy.Environments {
+		if environment != devEnv && environment != prodEnv {
+			return nil, errors.Errorf("unknown exposure policy environment %s, must be dev or prod", environment)
+		}
+		if err := validateFields(fields); err != nil {
+			return nil, err
+		}
+	}
+	for _, fields := range policy.ApiKeys {
+		if err := validateFields(fields); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// SetExposurePolicy sets the policy of the address responses, without a policy the environment defaults apply.
+// It must be called before HttpHandlerInit.
+func SetExposurePolicy(policy *ExposurePolicy) {
+	exposurePolicy = policy
+}
+
+// allowed returns the fields the policy allows for apiKey in the current environment.
+func (p *ExposurePolicy) allowed(apiKey string) []AddressField {
+	if apiKey != "" {
+		keyHash := sha256.Sum256([]byte(apiKey))
+		if fields, ok := p.ApiKeys[hex.EncodeToString(keyHash[:])]; ok {
+			return fields
+		}
+	}
+	environment := devEnv
+	if p.prod {
+		environment = prodEnv
+	}
+	if fields, ok := p.Environments[environment]; ok {
+		return fields
+	}
+	return defaultEnvironmentFields[environment]
+}
+
+// allowedSet returns the fields the policy allows for the API key of the request.
+func (p *ExposurePolicy) allowedSet(c *gin.Context) map[AddressField]bool {
+	allowed := make(map[AddressField]bool, len(allAddressFields))
+	for _, field := range p.allowed(c.GetHeader(ApiKeyHeader)) {
+		allowed[field] = true
+	}
+	return allowed
+}
+
+// authorize reports whether the request may receive every field of fields, otherwise it responds 403.
+func (p *ExposurePolicy) authorize(c *gin.Context, fields ...AddressField) bool {
+	allowed := p.allowedSet(c)
+	for _, field := range fields {
+		if !allowed[field] {
+			err := &FieldNotAllowedError{Field: field}
+			logger.Warn("secret withheld by the exposure policy", zap.Any("path", c.FullPath()), zap.Error(err))
+			c.JSONP(http.StatusForbidden, responseNoData(http.StatusForbidden, err.Error()))
+			return false
+		}
+	}
+	return true
+}
+
+// guard authorizes the secret fields of path before handler runs, the routes without secrets are returned as is.
+func (p *ExposurePolicy) guard(path string, handler webHandler) webHandler {
+	fields, ok := secretRouteFields[path]
+	if !ok {
+		return handler
+	}
+	return func(c *gin.Context) {
+		if p.authorize(c, fields...) {
+			handler(c)
+		}
+	}
+}
+
+// filter returns a copy of address without the fields that the policy or the include parameter of the request
+// withhold. Asking for a field the policy does not allow is an error.
+func (p *ExposurePolicy) filter(c *gin.Context, address *crypto.Address) (*crypto.Address, error) {
+	allowed := p.allowedSet(c)
+	exposed := allowed
+	if include := c.Query("include"); include != "" {
+		exposed = make(map[AddressField]bool, len(allAddressFields))
+		for _, name := range strings.Split(include, ",") {
+			field := AddressField(strings.TrimSpace(name))
+			if err := validateFields([]AddressField{field}); err != nil {
+				return nil, err
+			}
+			if !allowed[field] {
+				return nil, &FieldNotAllowedError{Field: field}
+			}
+			exposed[field] = true
+		}
+	}
+	filtered := *address
+	if !exposed[FieldAddress] {
+		filtered.Address = ""
+	}
+	if !exposed[FieldPublicKey] {
+		filtered.PublicKey, filtered.AccountPublicKey = "", ""
+	}
+	if !exposed[FieldPrivateKey] {
+		filtered.PrivateKey, filtered.WIF, filtered.RootPrivateKey = "", "", ""
+	}
+	if !exposed[FieldMnemonic] {
+		filtered.Mnemonic = ""
+	}
+	if !exposed[FieldSeed] {
+		filtered.Seed = ""
+	}
+	return &filtered, nil
+}
+
+func validateFields(fields []AddressField) error {
+	for _, field := range fields {
+		known := false
+		for _, addressField := range allAddressFields {
+			known = known || field == addressField
+		}
+		if !known {
+			return errors.Errorf("unknown address field %s, must be one of %v", field, allAddressFields)
+		}
+	}
+	return nil
+}
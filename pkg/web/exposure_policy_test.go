@@ -0,0 +1,201 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/pzhenzhou/crypto-prototype/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const exposureTestApiKey = "exposure-test-key"
+
+var exposureTestAddress = crypto.Address{
+	Address:          "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+	PublicKey:        "xpub-child",
+	PrivateKey:       "xprv-child",
+	Mnemonic:         "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	Seed:             "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc1",
+	Network:          "mainnet",
+	ScriptType:       "p2wpkh",
+	WIF:              "KyZpNDKnfs94vbrwhJneDi77V6jF64PWPF8x5cdJb8ifgg2DUc9d",
+	AccountPublicKey: "xpub-account",
+	RootPrivateKey:   "xprv-root",
+}
+
+func exposureTestContext(target string, apiKey string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	if apiKey != "" {
+		c.Request.Header.Set(ApiKeyHeader, apiKey)
+	}
+	return c, recorder
+}
+
+func exposureTestKeyHash() string {
+	keyHash := sha256.Sum256([]byte(exposureTestApiKey))
+	return hex.EncodeToString(keyHash[:])
+}
+
+func TestExposurePolicy_ProdDefault(t *testing.T) {
+	policy := &ExposurePolicy{prod: true}
+	c, _ := exposureTestContext("/segwit_address", "")
+	filtered, err := policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Address{
+		Address:          exposureTestAddress.Address,
+		PublicKey:        exposureTestAddress.PublicKey,
+		Network:          exposureTestAddress.Network,
+		ScriptType:       exposureTestAddress.ScriptType,
+		AccountPublicKey: exposureTestAddress.AccountPublicKey,
+	}, *filtered)
+	// the address is not modified
+	assert.Equal(t, "xprv-root", exposureTestAddress.RootPrivateKey)
+
+	// an API key without an entry gets the environment fields
+	c, _ = exposureTestContext("/segwit_address", "unknown-key")
+	filtered, err = policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Empty(t, filtered.PrivateKey)
+	assert.Empty(t, filtered.Mnemonic)
+}
+
+func TestExposurePolicy_DevDefault(t *testing.T) {
+	policy := &ExposurePolicy{}
+	c, _ := exposureTestContext("/segwit_address", "")
+	filtered, err := policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, exposureTestAddress, *filtered)
+}
+
+func TestExposurePolicy_ApiKey(t *testing.T) {
+	policy := &ExposurePolicy{
+		ApiKeys: map[string][]AddressField{exposureTestKeyHash(): {FieldAddress, FieldPrivateKey, FieldMnemonic}},
+		prod:    true,
+	}
+	c, _ := exposureTestContext("/segwit_address", exposureTestApiKey)
+	filtered, err := policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, exposureTestAddress.PrivateKey, filtered.PrivateKey)
+	assert.Equal(t, exposureTestAddress.WIF, filtered.WIF)
+	assert.Equal(t, exposureTestAddress.Mnemonic, filtered.Mnemonic)
+	// the API key entry replaces the environment fields
+	assert.Empty(t, filtered.PublicKey)
+	assert.Empty(t, filtered.Seed)
+
+	// the policy names the key by its hash, the hash itself is not a key
+	c, _ = exposureTestContext("/segwit_address", exposureTestKeyHash())
+	filtered, err = policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Empty(t, filtered.PrivateKey)
+}
+
+func TestExposurePolicy_Include(t *testing.T) {
+	policy := &ExposurePolicy{}
+	c, _ := exposureTestContext("/segwit_address?include=address,publicKey", "")
+	filtered, err := policy.filter(c, &exposureTestAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Address{
+		Address:          exposureTestAddress.Address,
+		PublicKey:        exposureTestAddress.PublicKey,
+		Network:          exposureTestAddress.Network,
+		ScriptType:       exposureTestAddress.ScriptType,
+		AccountPublicKey: exposureTestAddress.AccountPublicKey,
+	}, *filtered)
+
+	c, _ = exposureTestContext("/segwit_address?include=address,wif", "")
+	_, err = policy.filter(c, &exposureTestAddress)
+	assert.Error(t, err)
+	var notAllowed *FieldNotAllowedError
+	assert.False(t, errors.As(err, &notAllowed))
+}
+
+func TestExposurePolicy_IncludeNotAllowed(t *testing.T) {
+	policy := &ExposurePolicy{prod: true}
+	c, _ := exposureTestContext("/segwit_address?include=address,privateKey", "")
+	_, err := policy.filter(c, &exposureTestAddress)
+	var notAllowed *FieldNotAllowedError
+	assert.True(t, errors.As(err, &notAllowed))
+	assert.Equal(t, FieldPrivateKey, notAllowed.Field)
+
+	defaultPolicy := exposurePolicy
+	defer SetExposurePolicy(defaultPolicy)
+	SetExposurePolicy(policy)
+	c, _ = exposureTestContext("/segwit_address?include=seed", "")
+	code, rsp := responseWithData(c, nil, &exposureTestAddress)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, http.StatusForbidden, rsp.Code)
+	assert.Nil(t, rsp.Data)
+}
+
+func TestExposurePolicy_Guard(t *testing.T) {
+	called := false
+	handler := func(c *gin.Context) {
+		called = true
+		c.JSONP(http.StatusOK, Response{Code: http.StatusOK, Data: "secret"})
+	}
+	policy := &ExposurePolicy{
+		ApiKeys: map[string][]AddressField{exposureTestKeyHash(): {FieldMnemonic}},
+		prod:    true,
+	}
+	for _, path := range []string{"/mnemonic", "/aezeed/decode", "/slip39/combine", "/seedxor/split"} {
+		called = false
+		c, recorder := exposureTestContext(path, "")
+		policy.guard(path, handler)(c)
+		assert.False(t, called, path)
+		assert.Equal(t, http.StatusForbidden, recorder.Code, path)
+	}
+
+	c, recorder := exposureTestContext("/mnemonic", exposureTestApiKey)
+	policy.guard("/mnemonic", handler)(c)
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// the seed is still withheld from the key that only has the mnemonic
+	called = false
+	c, recorder = exposureTestContext("/aezeed/decode", exposureTestApiKey)
+	policy.guard("/aezeed/decode", handler)(c)
+	assert.False(t, called)
+	var rsp Response
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	assert.Equal(t, http.StatusForbidden, rsp.Code)
+
+	// the routes without secrets are not guarded
+	c, recorder = exposureTestContext("/mnemonic/validate", "")
+	policy.guard("/mnemonic/validate", handler)(c)
+	assert.True(t, called)
+
+	// dev returns everything
+	called = false
+	c, _ = exposureTestContext("/aezeed/decode", "")
+	(&ExposurePolicy{}).guard("/aezeed/decode", handler)(c)
+	assert.True(t, called)
+}
+
+func TestLoadExposurePolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "policy.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	policy, err := LoadExposurePolicy(write(`{"environments": {"prod": ["address", "seed"]}, "apiKeys": {"` +
+		exposureTestKeyHash() + `": ["privateKey"]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []AddressField{FieldAddress, FieldSeed}, policy.Environments[prodEnv])
+	assert.Equal(t, []AddressField{FieldPrivateKey}, policy.ApiKeys[exposureTestKeyHash()])
+
+	_, err = LoadExposurePolicy(write(`{"environments": {"staging": ["address"]}}`))
+	assert.Error(t, err)
+	_, err = LoadExposurePolicy(write(`{"apiKeys": {"key": ["wif"]}}`))
+	assert.Error(t, err)
+}
@@ -1,18 +1,23 @@
 package web
 
 import (
+	"encoding/hex"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	common "github.com/pzhenzhou/crypto-prototype/pkg"
 	"github.com/pzhenzhou/crypto-prototype/pkg/crypto"
+	"github.com/tyler-smith/go-bip32"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	errorMessageFormat = "Request parameter is invalid Name:%s, value: %s"
+	// translateSeedWarning the BIP39 seed is PBKDF2 over the words, a translated mnemonic keeps the entropy only
+	translateSeedWarning = "The translated mnemonic has the same entropy but a different seed, it derives different keys and addresses and does not restore the original wallet"
 )
 
 type webHandler = func(c *gin.Context)
@@ -32,7 +37,8 @@ func responseNoData(code int, message string) Response {
 	return Response{Code: code, Message: message, Data: nil}
 }
 
-func responseWithData(err error, address *crypto.Address) (int, Response) {
+// responseWithData responds the address with the fields the exposure policy and the include parameter let through.
+func responseWithData(c *gin.Context, err error, address *crypto.Address) (int, Response) {
 	if err != nil {
 		return http.StatusInternalServerError, Response{
 			http.StatusInternalServerError,
@@ -40,6 +46,15 @@ func responseWithData(err error, address *crypto.Address) (int, Response) {
 			nil,
 		}
 	}
+	address, err = exposurePolicy.filter(c, address)
+	if err != nil {
+		code := http.StatusBadRequest
+		if _, ok := err.(*FieldNotAllowedError); ok {
+			code = http.StatusForbidden
+		}
+		logger.Warn("address fields withheld by the exposure policy", zap.Any("include", c.Query("include")), zap.Error(err))
+		return code, responseNoData(code, err.Error())
+	}
 	return http.StatusOK, Response{
 		http.StatusOK,
 		"",
@@ -47,17 +62,121 @@ func responseWithData(err error, address *crypto.Address) (int, Response) {
 	}
 }
 
+func badRequest(c *gin.Context, name string, value string) {
+	logger.Warn("invalid request parameter", zap.Any(name, value))
+	c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, fmt.Sprintf(errorMessageFormat, name, value)))
+}
+
+func queryLanguage(c *gin.Context) common.Language {
+	return common.Language(strings.ToLower(c.DefaultQuery("lang", string(common.English))))
+}
+
+// queryNetwork adds the network query parameter to args, the generators use their default network without it.
+func queryNetwork(c *gin.Context, args map[crypto.GenerateArgs]interface{}) bool {
+	name := c.Query("network")
+	if name == "" {
+		return true
+	}
+	network, err := crypto.ParseNetwork(name)
+	if err != nil {
+		badRequest(c, "network", name)
+		return false
+	}
+	args[crypto.InputNetwork] = network
+	return true
+}
+
+// queryScriptType adds the scriptType query parameter to args, the path purpose selects it without the parameter.
+func queryScriptType(c *gin.Context, args map[crypto.GenerateArgs]interface{}) bool {
+	name := c.Query("scriptType")
+	if name == "" {
+		return true
+	}
+	scriptType, err := crypto.ParseScriptType(name)
+	if err != nil {
+		badRequest(c, "scriptType", name)
+		return false
+	}
+	args[crypto.InputScriptType] = scriptType
+	return true
+}
+
+// queryIncludeRootKey adds the includeRootKey opt-in to args, the master xprv is never returned without it.
+func queryIncludeRootKey(c *gin.Context, args map[crypto.GenerateArgs]interface{}) bool {
+	value := c.DefaultQuery("includeRootKey", "false")
+	includeRootKey, err := strconv.ParseBool(value)
+	if err != nil {
+		badRequest(c, "includeRootKey", value)
+		return false
+	}
+	args[crypto.InputIncludeRootKey] = includeRootKey
+	return true
+}
+
+// querySlip132 adds the slip132 opt-in to args, the extended keys keep the BIP32 xpub and tpub versions without it.
+func querySlip132(c *gin.Context, args map[crypto.GenerateArgs]interface{}) bool {
+	value := c.DefaultQuery("slip132", "false")
+	slip132, err := strconv.ParseBool(value)
+	if err != nil {
+		badRequest(c, "slip132", value)
+		return false
+	}
+	args[crypto.InputSlip132] = slip132
+	return true
+}
+
+func queryWordCount(c *gin.Context) (crypto.WordCount, bool) {
+	words := c.DefaultQuery("words", "12")
+	wordCount, err := strconv.Atoi(words)
+	if err != nil {
+		badRequest(c, "words", words)
+		return 0, false
+	}
+	return crypto.WordCount(wordCount), true
+}
+
 var (
 	addressGeneratorCaller map[string]crypto.AddressGenerator
+	seedGenerator          *crypto.SeedGenerator
 	httpRouter             = map[string][]string{
-		"GET": {"/check_health", "/segwit_address", "/segwit_address_from_seed", "/multisig_address/:m/:n/:pks"},
+		"GET": {"/check_health", "/segwit_address", "/segwit_address_from_seed", "/taproot_address", "/multisig_address/:m/:n/:pks",
+			"/mnemonic", "/mnemonic/validate", "/mnemonic/entropy", "/mnemonic/from_entropy", "/mnemonic/from_user_entropy",
+			"/mnemonic/complete",
+			"/mnemonic/expand", "/mnemonic/final_words", "/mnemonic/translate",
+			"/mnemonic/detect_language", "/mnemonic/split", "/mnemonic/combine", "/slip39/split",
+			"/slip39/combine", "/seedxor/split", "/seedxor/combine",
+			"/electrum/mnemonic", "/electrum/validate", "/aezeed/mnemonic", "/aezeed/decode", "/bip85",
+			"/extended_key/convert"},
 	}
 
 	handlerFunc = map[string]webHandler{
 		"/check_health":                checkHealth(),
 		"/segwit_address":              segWitAddressHandler(),
 		"/segwit_address_from_seed":    sedWitAddressFromSeedHandler(),
+		"/taproot_address":             taprootAddressHandler(),
 		"/multisig_address/:m/:n/:pks": multiSigHandler(),
+		"/mnemonic":                    newMnemonicHandler(),
+		"/mnemonic/validate":           validateMnemonicHandler(),
+		"/mnemonic/entropy":            mnemonicToEntropyHandler(),
+		"/mnemonic/from_entropy":       entropyToMnemonicHandler(),
+		"/mnemonic/from_user_entropy":  userEntropyMnemonicHandler(),
+		"/mnemonic/complete":           completeWordHandler(),
+		"/mnemonic/expand":             expandMnemonicHandler(),
+		"/mnemonic/final_words":        finalWordsHandler(),
+		"/mnemonic/translate":          translateMnemonicHandler(),
+		"/mnemonic/detect_language":    detectLanguageHandler(),
+		"/mnemonic/split":              splitMnemonicHandler(),
+		"/mnemonic/combine":            combineMnemonicHandler(),
+		"/slip39/split":                slip39SplitHandler(),
+		"/slip39/combine":              slip39CombineHandler(),
+		"/seedxor/split":               seedXorSplitHandler(),
+		"/seedxor/combine":             seedXorCombineHandler(),
+		"/electrum/mnemonic":           newElectrumMnemonicHandler(),
+		"/electrum/validate":           validateElectrumMnemonicHandler(),
+		"/aezeed/mnemonic":             newAezeedHandler(),
+		"/aezeed/decode":               decodeAezeedHandler(),
+		"/bip85":                       bip85Handler(),
+		"/extended_key/convert":        convertExtendedKeyHandler(),
 	}
 	logger = common.GetLogger()
 )
@@ -65,12 +184,14 @@ var (
 func HttpHandlerInit(port int) {
 	if common.IsProd() {
 		gin.SetMode(gin.ReleaseMode)
+		exposurePolicy.prod = true
 	}
 	addressGeneratorCaller = crypto.AddGeneratorCaller()
+	seedGenerator = crypto.GetSeedGenerator(common.GetWordList())
 	router := gin.Default()
 	for httpMethod, pathSlices := range httpRouter {
 		for _, path := range pathSlices {
-			router.Handle(httpMethod, path, handlerFunc[path])
+			router.Handle(httpMethod, path, exposurePolicy.guard(path, handlerFunc[path]))
 		}
 	}
 	var startErr = router.Run(":" + strconv.Itoa(port))
@@ -114,57 +235,739 @@ func multiSigHandler() webHandler {
 			crypto.MultiSigNum:       multiPair,
 			crypto.MultiSigPublicKey: pksBytes,
 		}
+		if !queryNetwork(c, args) {
+			return
+		}
 		address, err := addressGeneratorCaller[crypto.NofMMultiSigAddressGenerator].Generate(args)
-		code, rsp := responseWithData(err, address)
+		code, rsp := responseWithData(c, err, address)
 		c.JSONP(code, rsp)
 	}
 }
 
 func sedWitAddressFromSeedHandler() webHandler {
 	return func(c *gin.Context) {
-		checkPath(c)
+		if !checkPath(c) {
+			return
+		}
 		path := strings.ReplaceAll(c.Query("path"), "\"", "")
 		args := map[crypto.GenerateArgs]interface{}{
 			crypto.InputSeed: c.Query("seed"),
 			crypto.InputPath: path,
 		}
+		if !queryNetwork(c, args) || !queryScriptType(c, args) || !queryIncludeRootKey(c, args) || !querySlip132(c, args) {
+			return
+		}
 		address, err := addressGeneratorCaller[crypto.HDSegWitAddressGenerator].Generate(args)
-		code, rsp := responseWithData(err, address)
+		code, rsp := responseWithData(c, err, address)
 		c.JSONP(code, rsp)
 	}
 }
 
-func checkPath(c *gin.Context) {
-	path := c.Query("path")
-	if len(path) == 0 || path == "" {
-		logger.Warn("MultiSig invalid request parameter", zap.Any("path", path))
+// checkPath reports whether the path parameter is a BIP44 style path of a known purpose, otherwise it responds 400.
+func checkPath(c *gin.Context) bool {
+	path := strings.ReplaceAll(c.Query("path"), "\"", "")
+	if len(path) == 0 || !common.IsInvalidPath(path) {
+		logger.Warn("invalid request parameter", zap.Any("path", path))
 		c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, fmt.Sprintf(errorMessageFormat, "path", path)))
-	} else {
-		path = strings.ReplaceAll(path, "\"", "")
-		if !common.IsInvalidPath(path) {
-			logger.Warn("MultiSig invalid request parameter", zap.Any("path", path))
-			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, fmt.Sprintf(errorMessageFormat, "path", path)))
-		}
+		return false
 	}
+	return true
 }
 
 func segWitAddressHandler() webHandler {
+	return mnemonicAddressHandler(crypto.HDSegWitAddressGenerator)
+}
+
+func taprootAddressHandler() webHandler {
+	return mnemonicAddressHandler(crypto.TaprootAddressGenerator)
+}
+
+// mnemonicAddressHandler generates the address of path from the mnemonic (or a new one) with the generator.
+func mnemonicAddressHandler(generator string) webHandler {
 	return func(c *gin.Context) {
-		checkPath(c)
+		if !checkPath(c) {
+			return
+		}
 		args := make(map[crypto.GenerateArgs]interface{})
 		args[crypto.InputPath] = strings.ReplaceAll(c.Query("path"), "\"", "")
 		if len(c.Query("mnemonic")) > 0 || c.Query("mnemonic") != "" {
 			args[crypto.InputMnemonic] = c.Query("mnemonic")
+		} else {
+			wordCount, ok := queryWordCount(c)
+			if !ok {
+				return
+			}
+			args[crypto.InputWordCount] = wordCount
+		}
+		if format := c.Query("format"); format != "" {
+			args[crypto.InputMnemonicFormat] = crypto.MnemonicFormat(strings.ToLower(format))
 		}
+		args[crypto.InputLanguage] = queryLanguage(c)
 		args[crypto.InputPassword] = c.Query("password")
-		address, err := addressGeneratorCaller[crypto.HDSegWitAddressGenerator].Generate(args)
-		code, rsp := responseWithData(err, address)
+		if !queryNetwork(c, args) || !queryScriptType(c, args) || !queryIncludeRootKey(c, args) || !querySlip132(c, args) {
+			return
+		}
+		address, err := addressGeneratorCaller[generator].Generate(args)
+		code, rsp := responseWithData(c, err, address)
 		c.JSONP(code, rsp)
 	}
 }
 
+func newMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		wordCount, ok := queryWordCount(c)
+		if !ok {
+			return
+		}
+		mnemonic, err := seedGenerator.NewMnemonic(queryLanguage(c), wordCount)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+			},
+		})
+	}
+}
+
+func validateMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		language := queryLanguage(c)
+		if err := seedGenerator.ValidateMnemonic(language, mnemonic); err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"valid":     true,
+				"language":  language,
+				"wordCount": len(strings.Fields(mnemonic)),
+			},
+		})
+	}
+}
+
+func mnemonicToEntropyHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		entropy, err := seedGenerator.MnemonicToEntropy(queryLanguage(c), mnemonic)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"entropy": hex.EncodeToString(entropy),
+			},
+		})
+	}
+}
+
+func entropyToMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		entropyHex := strings.ReplaceAll(c.Query("entropy"), "\"", "")
+		entropy, err := hex.DecodeString(entropyHex)
+		if err != nil || len(entropy) == 0 {
+			badRequest(c, "entropy", entropyHex)
+			return
+		}
+		mnemonic, err := seedGenerator.EntropyToMnemonic(entropy, queryLanguage(c))
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+			},
+		})
+	}
+}
+
+func userEntropyMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		wordCount, ok := queryWordCount(c)
+		if !ok {
+			return
+		}
+		format := crypto.UserEntropyFormat(strings.ToLower(c.Query("format")))
+		value := strings.ReplaceAll(c.Query("entropy"), "\"", "")
+		if value == "" {
+			badRequest(c, "entropy", value)
+			return
+		}
+		mix := c.DefaultQuery("mix", "false") == "true"
+		mnemonic, err := seedGenerator.UserEntropyMnemonic(queryLanguage(c), wordCount, format, value, mix)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+				"mixed":    mix,
+			},
+		})
+	}
+}
+
+func completeWordHandler() webHandler {
+	return func(c *gin.Context) {
+		prefix := strings.ReplaceAll(c.Query("prefix"), "\"", "")
+		if strings.TrimSpace(prefix) == "" {
+			badRequest(c, "prefix", prefix)
+			return
+		}
+		words, err := seedGenerator.CompleteWord(queryLanguage(c), prefix)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"words": words,
+			},
+		})
+	}
+}
+
+func expandMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		language := queryLanguage(c)
+		expanded, err := seedGenerator.ExpandMnemonic(language, mnemonic)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": expanded,
+				"valid":    seedGenerator.ValidateMnemonic(language, expanded) == nil,
+			},
+		})
+	}
+}
+
+func finalWordsHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		words, err := seedGenerator.FinalWords(queryLanguage(c), mnemonic)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"words": words,
+			},
+		})
+	}
+}
+
+func translateMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		to := common.Language(strings.ToLower(c.Query("to")))
+		if to == "" {
+			badRequest(c, "to", string(to))
+			return
+		}
+		from := common.Language(strings.ToLower(c.Query("from")))
+		translated, from, err := seedGenerator.TranslateMnemonic(from, to, mnemonic)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		seedChanged := from != to
+		rsp := Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic":    translated,
+				"from":        from,
+				"to":          to,
+				"seedChanged": seedChanged,
+			},
+		}
+		if seedChanged {
+			rsp.Message = translateSeedWarning
+		}
+		c.JSONP(http.StatusOK, rsp)
+	}
+}
+
+func detectLanguageHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		language, err := seedGenerator.DetectLanguage(mnemonic)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"lang": language,
+			},
+		})
+	}
+}
+
+func splitMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		threshold, err := strconv.Atoi(c.Query("threshold"))
+		if err != nil {
+			badRequest(c, "threshold", c.Query("threshold"))
+			return
+		}
+		shareCount, err := strconv.Atoi(c.Query("shares"))
+		if err != nil {
+			badRequest(c, "shares", c.Query("shares"))
+			return
+		}
+		shares, err := seedGenerator.SplitMnemonic(queryLanguage(c), mnemonic, threshold, shareCount)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"threshold": threshold,
+				"shares":    shares,
+			},
+		})
+	}
+}
+
+func combineMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		shares := c.QueryArray("share")
+		if len(shares) == 0 {
+			badRequest(c, "share", "")
+			return
+		}
+		for i, share := range shares {
+			shares[i] = strings.ReplaceAll(share, "\"", "")
+		}
+		mnemonic, err := seedGenerator.CombineMnemonicShares(queryLanguage(c), shares)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+			},
+		})
+	}
+}
+
+// parseSlip39Groups parses groups written as "2of3,3of5" into member thresholds and member counts.
+func parseSlip39Groups(value string) ([]crypto.Slip39Group, bool) {
+	groups := make([]crypto.Slip39Group, 0)
+	for _, group := range strings.Split(value, ",") {
+		pair := strings.Split(strings.TrimSpace(group), "of")
+		if len(pair) != 2 {
+			return nil, false
+		}
+		threshold, thresholdErr := strconv.Atoi(pair[0])
+		count, countErr := strconv.Atoi(pair[1])
+		if thresholdErr != nil || countErr != nil {
+			return nil, false
+		}
+		groups = append(groups, crypto.Slip39Group{MemberThreshold: threshold, MemberCount: count})
+	}
+	return groups, true
+}
+
+func slip39SplitHandler() webHandler {
+	return func(c *gin.Context) {
+		secretHex := c.Query("secret")
+		var secret []byte
+		if secretHex == "" {
+			bits, err := strconv.Atoi(c.DefaultQuery("bits", "128"))
+			if err != nil {
+				badRequest(c, "bits", c.Query("bits"))
+				return
+			}
+			if secret, err = seedGenerator.NewSlip39Secret(bits); err == crypto.Slip39SecretLenInvalid {
+				badRequest(c, "bits", c.Query("bits"))
+				return
+			} else if err != nil {
+				c.JSONP(http.StatusInternalServerError, responseNoData(http.StatusInternalServerError, err.Error()))
+				return
+			}
+		} else if decoded, err := hex.DecodeString(secretHex); err != nil {
+			badRequest(c, "secret", secretHex)
+			return
+		} else {
+			secret = decoded
+		}
+		groups, ok := parseSlip39Groups(c.DefaultQuery("groups", "1of1"))
+		if !ok {
+			badRequest(c, "groups", c.Query("groups"))
+			return
+		}
+		groupThreshold, err := strconv.Atoi(c.DefaultQuery("groupThreshold", "1"))
+		if err != nil {
+			badRequest(c, "groupThreshold", c.Query("groupThreshold"))
+			return
+		}
+		exponent, err := strconv.Atoi(c.DefaultQuery("exponent", "1"))
+		if err != nil {
+			badRequest(c, "exponent", c.Query("exponent"))
+			return
+		}
+		extendable := c.DefaultQuery("extendable", "true") == "true"
+		mnemonics, err := seedGenerator.SplitSlip39Secret(secret, c.Query("passphrase"), groupThreshold, groups, exponent, extendable)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"groupThreshold": groupThreshold,
+				"groups":         mnemonics,
+			},
+		})
+	}
+}
+
+func slip39CombineHandler() webHandler {
+	return func(c *gin.Context) {
+		shares := c.QueryArray("share")
+		if len(shares) == 0 {
+			badRequest(c, "share", "")
+			return
+		}
+		for i, share := range shares {
+			shares[i] = strings.ReplaceAll(share, "\"", "")
+		}
+		secret, err := crypto.CombineSlip39Shares(shares, c.Query("passphrase"))
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"masterSecret": hex.EncodeToString(secret),
+			},
+		})
+	}
+}
+
+func seedXorSplitHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		parts, err := strconv.Atoi(c.DefaultQuery("parts", "3"))
+		if err != nil {
+			badRequest(c, "parts", c.Query("parts"))
+			return
+		}
+		mnemonics, err := seedGenerator.SplitSeedXor(queryLanguage(c), mnemonic, parts)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"parts": mnemonics,
+			},
+		})
+	}
+}
+
+func seedXorCombineHandler() webHandler {
+	return func(c *gin.Context) {
+		parts := c.QueryArray("part")
+		if len(parts) == 0 {
+			badRequest(c, "part", "")
+			return
+		}
+		for i, part := range parts {
+			parts[i] = strings.ReplaceAll(part, "\"", "")
+		}
+		mnemonic, err := seedGenerator.CombineSeedXor(queryLanguage(c), parts)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+			},
+		})
+	}
+}
+
+func newElectrumMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		seedType := crypto.ElectrumSeedType(c.DefaultQuery("type", string(crypto.ElectrumSegwit)))
+		mnemonic, err := seedGenerator.NewElectrumMnemonic(queryLanguage(c), seedType)
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+				"seedType": seedType,
+			},
+		})
+	}
+}
+
+func validateElectrumMnemonicHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		seedType, ok := crypto.ElectrumSeedVersion(mnemonic)
+		if !ok {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, crypto.NotElectrumSeed.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"seedType": seedType,
+			},
+		})
+	}
+}
+
+func newAezeedHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic, seed, err := seedGenerator.NewAezeed(c.Query("password"), time.Now())
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"mnemonic": mnemonic,
+				"birthday": seed.BirthdayTime().UTC().Format("2006-01-02"),
+			},
+		})
+	}
+}
+
+func decodeAezeedHandler() webHandler {
+	return func(c *gin.Context) {
+		mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+		if mnemonic == "" {
+			badRequest(c, "mnemonic", mnemonic)
+			return
+		}
+		seed, err := seedGenerator.DecodeAezeed(mnemonic, c.Query("password"))
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"internalVersion": seed.InternalVersion,
+				"birthday":        seed.BirthdayTime().UTC().Format("2006-01-02"),
+				"seed":            hex.EncodeToString(seed.Entropy[:]),
+			},
+		})
+	}
+}
+
+// bip85MasterKey reads the BIP85 master key from xprv, or from mnemonic and password.
+func bip85MasterKey(c *gin.Context) (*bip32.Key, bool) {
+	if xprv := c.Query("xprv"); xprv != "" {
+		master, err := bip32.B58Deserialize(xprv)
+		if err != nil {
+			badRequest(c, "xprv", xprv)
+			return nil, false
+		}
+		return master, true
+	}
+	mnemonic := strings.ReplaceAll(c.Query("mnemonic"), "\"", "")
+	if mnemonic == "" {
+		badRequest(c, "mnemonic", mnemonic)
+		return nil, false
+	}
+	if err := seedGenerator.ValidateMnemonic(queryLanguage(c), mnemonic); err != nil {
+		c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+		return nil, false
+	}
+	master, err := bip32.NewMasterKey(seedGenerator.NewSeed(mnemonic, c.Query("password")))
+	if err != nil {
+		c.JSONP(http.StatusInternalServerError, responseNoData(http.StatusInternalServerError, err.Error()))
+		return nil, false
+	}
+	return master, true
+}
+
+// bip85AppFields the exposure policy field group of the secret of every BIP85 application
+var bip85AppFields = map[crypto.Bip85App]AddressField{
+	crypto.Bip85AppBIP39:     FieldMnemonic,
+	crypto.Bip85AppWIF:       FieldPrivateKey,
+	crypto.Bip85AppXPRV:      FieldPrivateKey,
+	crypto.Bip85AppHex:       FieldSeed,
+	crypto.Bip85AppPWDBase64: FieldSeed,
+}
+
+func bip85Handler() webHandler {
+	return func(c *gin.Context) {
+		master, ok := bip85MasterKey(c)
+		if !ok {
+			return
+		}
+		app := c.DefaultQuery("app", strconv.Itoa(int(crypto.Bip85AppBIP39)))
+		appValue, err := strconv.Atoi(app)
+		if err != nil {
+			badRequest(c, "app", app)
+			return
+		}
+		index := c.DefaultQuery("index", "0")
+		indexValue, err := strconv.ParseUint(index, 10, 31)
+		if err != nil {
+			badRequest(c, "index", index)
+			return
+		}
+		if field, ok := bip85AppFields[crypto.Bip85App(appValue)]; ok && !exposurePolicy.authorize(c, field) {
+			return
+		}
+		data := map[string]interface{}{
+			"app":   appValue,
+			"index": indexValue,
+		}
+		switch crypto.Bip85App(appValue) {
+		case crypto.Bip85AppBIP39:
+			wordCount, ok := queryWordCount(c)
+			if !ok {
+				return
+			}
+			data["mnemonic"], err = seedGenerator.Bip85Mnemonic(master, queryLanguage(c), wordCount, uint32(indexValue))
+		case crypto.Bip85AppWIF:
+			data["wif"], err = crypto.Bip85WIF(master, uint32(indexValue))
+		case crypto.Bip85AppXPRV:
+			data["xprv"], err = crypto.Bip85XPRV(master, uint32(indexValue))
+		case crypto.Bip85AppHex:
+			size := c.DefaultQuery("bytes", "64")
+			sizeValue, convErr := strconv.Atoi(size)
+			if convErr != nil {
+				badRequest(c, "bytes", size)
+				return
+			}
+			data["hex"], err = crypto.Bip85Hex(master, sizeValue, uint32(indexValue))
+		case crypto.Bip85AppPWDBase64:
+			length := c.DefaultQuery("length", "21")
+			lengthValue, convErr := strconv.Atoi(length)
+			if convErr != nil {
+				badRequest(c, "length", length)
+				return
+			}
+			data["password"], err = crypto.Bip85PasswordBase64(master, lengthValue, uint32(indexValue))
+		default:
+			err = crypto.Bip85AppInvalid
+		}
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: data,
+		})
+	}
+}
+
 func checkHealth() webHandler {
 	return func(c *gin.Context) {
 		c.String(http.StatusOK, "I'm Ok")
 	}
 }
+
+// convertExtendedKeyHandler rewrites the SLIP-132 version of an extended key, e.g. xpub to zpub.
+func convertExtendedKeyHandler() webHandler {
+	return func(c *gin.Context) {
+		key := strings.ReplaceAll(c.Query("key"), "\"", "")
+		if key == "" {
+			badRequest(c, "key", key)
+			return
+		}
+		to, err := crypto.ParseKeyFormat(c.Query("to"))
+		if err != nil {
+			badRequest(c, "to", c.Query("to"))
+			return
+		}
+		from, err := crypto.ExtendedKeyFormat(key)
+		if err == nil {
+			key, err = crypto.ConvertExtendedKey(key, to)
+		}
+		if err != nil {
+			c.JSONP(http.StatusBadRequest, responseNoData(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if parsed, err := bip32.B58Deserialize(key); err == nil && parsed.IsPrivate && !exposurePolicy.authorize(c, FieldPrivateKey) {
+			return
+		}
+		c.JSONP(http.StatusOK, Response{
+			Code: http.StatusOK,
+			Data: map[string]interface{}{
+				"key":  key,
+				"from": from,
+				"to":   to,
+			},
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/pzhenzhou/crypto-prototype/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCheckPath(t *testing.T) {
+	c, _ := exposureTestContext("/segwit_address?path=m/84'/0'/0'/0/0", "")
+	assert.True(t, checkPath(c))
+
+	for _, target := range []string{"/segwit_address", "/segwit_address?path=m", "/segwit_address?path=m/1'/0'/0'/0/0"} {
+		c, recorder := exposureTestContext(target, "")
+		assert.False(t, checkPath(c), target)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, target)
+	}
+}
+
+// An invalid path ends the request with the single 400 response of checkPath.
+func TestMnemonicAddressHandler_InvalidPath(t *testing.T) {
+	for target, handler := range map[string]webHandler{
+		"/segwit_address?path=m&format=electrum&mnemonic=bitter+grass+shiver+impose+acquire+brush+forget+axis+eager+alone+wine+silver": mnemonicAddressHandler(crypto.HDSegWitAddressGenerator),
+		"/segwit_address_from_seed?path=m&seed=000102030405060708090a0b0c0d0e0f":                                                       sedWitAddressFromSeedHandler(),
+	} {
+		c, recorder := exposureTestContext(target, "")
+		handler(c)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, target)
+		var rsp Response
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp), target)
+		assert.Equal(t, http.StatusBadRequest, rsp.Code, target)
+	}
+}
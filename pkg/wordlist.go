@@ -0,0 +1,151 @@
+package common
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// ManifestFileName is the manifest looked up in the config directory.
+	ManifestFileName = "manifest.json"
+	// WordListSize BIP39 word lists always have 2048 words, one for every 11 bits value.
+	WordListSize    = 2048
+	uniquePrefixLen = 4
+)
+
+// WordListManifest declares every word list the service is allowed to load.
+// A language that is not declared in the manifest is not supported, even if its file is in the config directory.
+type WordListManifest struct {
+	WordLists []WordListEntry `json:"wordLists"`
+}
+
+// WordListEntry pins the word list of one language.
+// Sha256 is the digest of the words joined by "\n" with a trailing "\n", which is the layout of the official BIP39 files.
+// Sorted and UniquePrefix enable the ordering and the 4-character prefix checks for lists that are designed for them,
+// both compare the words without accents.
+type WordListEntry struct {
+	Language     Language `json:"language"`
+	File         string   `json:"file"`
+	Sha256       string   `json:"sha256"`
+	Sorted       bool     `json:"sorted"`
+	UniquePrefix bool     `json:"uniquePrefix"`
+}
+
+func ReadManifest(fsys fs.FS) (*WordListManifest, error) {
+	rawBytes, err := fs.ReadFile(fsys, ManifestFileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "read word list manifest")
+	}
+	var manifest WordListManifest
+	if err := json.Unmarshal(rawBytes, &manifest); err != nil {
+		return nil, errors.Wrap(err, "parse word list manifest")
+	}
+	if len(manifest.WordLists) == 0 {
+		return nil, errors.New("word list manifest declares no language")
+	}
+	return &manifest, nil
+}
+
+// Entry returns the entry declared for language.
+func (m *WordListManifest) Entry(language Language) (WordListEntry, bool) {
+	for _, entry := range m.WordLists {
+		if entry.Language == language {
+			return entry, true
+		}
+	}
+	return WordListEntry{}, false
+}
+
+// Load reads the word list file of the entry from fsys and verifies it.
+func (e WordListEntry) Load(fsys fs.FS) ([]string, error) {
+	filePtr, err := fsys.Open(e.File)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s word list", e.Language)
+	}
+	defer filePtr.Close()
+	words, err := readWords(filePtr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s word list", e.Language)
+	}
+	if err := e.Verify(words); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// Verify checks the size, the uniqueness, when enabled the prefix uniqueness and the order, and at last the digest of words.
+func (e WordListEntry) Verify(words []string) error {
+	if len(words) != WordListSize {
+		return errors.Errorf("%s word list must have %d words, actual %d", e.Language, WordListSize, len(words))
+	}
+	seen := make(map[string]int, len(words))
+	prefixes := make(map[string]int, len(words))
+	folded := make([]string, len(words))
+	for i, word := range words {
+		if first, ok := seen[word]; ok {
+			return errors.Errorf("%s word list has duplicate word %q at line %d and %d", e.Language, word, first+1, i+1)
+		}
+		seen[word] = i
+		folded[i] = FoldWord(word)
+		if !e.UniquePrefix {
+			continue
+		}
+		prefix := folded[i]
+		if runes := []rune(prefix); len(runes) > uniquePrefixLen {
+			prefix = string(runes[:uniquePrefixLen])
+		}
+		if first, ok := prefixes[prefix]; ok {
+			return errors.Errorf("%s word list words %q and %q share the prefix %q", e.Language, words[first], word, prefix)
+		}
+		prefixes[prefix] = i
+	}
+	if e.Sorted && !sort.StringsAreSorted(folded) {
+		return errors.Errorf("%s word list is not sorted", e.Language)
+	}
+	if digest := wordsDigest(words); !strings.EqualFold(digest, e.Sha256) {
+		return errors.Errorf("%s word list sha256 mismatch. expected %s, actual %s", e.Language, e.Sha256, digest)
+	}
+	return nil
+}
+
+func readWords(reader io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(bufio.ScanWords)
+	wordBuf := make([]string, 0, WordListSize)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		wordBuf = append(wordBuf, strings.TrimSpace(scanner.Text()))
+	}
+	return wordBuf, scanner.Err()
+}
+
+func wordsDigest(words []string) string {
+	hash := sha256.New()
+	for _, word := range words {
+		hash.Write([]byte(word))
+		hash.Write([]byte("\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// FoldWord removes the accents so that "é" and "e" compare equal, as the BIP39 word list rules do.
+func FoldWord(word string) string {
+	var builder strings.Builder
+	for _, r := range norm.NFKD.String(word) {
+		if !unicode.Is(unicode.Mn, r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"github.com/pzhenzhou/crypto-prototype/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+const testConfigPath = "../config"
+
+func TestEmbeddedWordsList(t *testing.T) {
+	manifest, err := ReadManifest(config.WordLists)
+	assert.NoError(t, err)
+	for _, entry := range manifest.WordLists {
+		assert.True(t, IsSupportLanguage(entry.Language), entry.Language)
+		assert.Len(t, GetWordList()[entry.Language], WordListSize, entry.Language)
+	}
+	// every official BIP39 word list is shipped
+	assert.Len(t, manifest.WordLists, 10)
+	assert.True(t, IsSupportLanguage(Portuguese))
+}
+
+func TestLoadWordsList(t *testing.T) {
+	assert.NoError(t, LoadWordsList(testConfigPath))
+	// an empty directory keeps the embedded word lists
+	assert.NoError(t, LoadWordsList(t.TempDir()))
+	assert.Len(t, GetWordList()[English], WordListSize)
+}
+
+func TestLoadWordsList_TamperedOverride(t *testing.T) {
+	words := append([]string{}, GetWordList()[English]...)
+	words[0], words[1] = words[1], words[0]
+	overridePath := t.TempDir()
+	assert.NoError(t, os.WriteFile(path.Join(overridePath, "english.txt"), []byte(strings.Join(words, "\n")), 0600))
+	assert.Error(t, LoadWordsList(overridePath))
+
+	manifest := `{"wordLists": [{"language": "english", "file": "english.txt", "sha256": "00"}]}`
+	assert.NoError(t, os.WriteFile(path.Join(overridePath, ManifestFileName), []byte(manifest), 0600))
+	assert.Error(t, LoadWordsList(overridePath))
+	assert.Equal(t, "abandon", GetWordList()[English][0])
+}
+
+func TestWordListEntry_Verify(t *testing.T) {
+	manifest, err := ReadManifest(os.DirFS(testConfigPath))
+	assert.NoError(t, err)
+	entry, ok := manifest.Entry(English)
+	assert.True(t, ok)
+	words, err := entry.Load(os.DirFS(testConfigPath))
+	assert.NoError(t, err)
+
+	assert.Error(t, entry.Verify(words[1:]))
+
+	duplicate := append([]string{}, words...)
+	duplicate[1] = duplicate[0]
+	assert.Error(t, entry.Verify(duplicate))
+
+	prefix := append([]string{}, words...)
+	prefix[1] = words[0] + "x"
+	assert.Error(t, entry.Verify(prefix))
+
+	swapped := append([]string{}, words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	assert.Error(t, entry.Verify(swapped))
+
+	tampered := append([]string{}, words...)
+	tampered[WordListSize-1] = "zzzz"
+	entry.Sorted = false
+	entry.UniquePrefix = false
+	assert.Error(t, entry.Verify(tampered))
+}